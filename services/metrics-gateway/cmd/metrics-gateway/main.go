@@ -3,39 +3,159 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
 
+	"metrics-gateway/internal/bench"
+	"metrics-gateway/internal/faultinjector"
+	"metrics-gateway/internal/ingest"
+	"metrics-gateway/internal/ingest/wal"
 	"metrics-gateway/internal/metrics"
+	"metrics-gateway/internal/metrics/graphite"
 	"metrics-gateway/internal/server"
 	"metrics-gateway/internal/simulator"
+
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 func main() {
+	mode := flag.String("mode", "sim", "which Collector drives AllMetrics: sim (randomized), host (read real system data), or hybrid (host data, falling back to sim per-subsystem)")
+	diskIDsFlag := flag.String("disks", "disk0,disk1,disk2,disk3", "comma-separated disk IDs; in host/hybrid mode these must be real block device names (e.g. sda,sdb)")
+	raidIDsFlag := flag.String("raids", "raid0,raid1,raid3", "comma-separated RAID IDs; in host/hybrid mode these must be real md device names (e.g. md0,md1)")
+	scenarioFlag := flag.String("scenario", "", "built-in scenario name (small-random-io, large-sequential, degraded-rebuild, flaky-disk) or a path to a scenario YAML/JSON file; only used in sim/hybrid mode, empty keeps the Simulator's built-in random ranges")
+	seedFlag := flag.Int64("seed", 0, "PRNG seed for sim mode; 0 (the default) seeds from the current time, so pass an explicit nonzero seed for a reproducible run")
+	recordFlag := flag.String("record", "", "path to write a trace.jsonl of every tick's metric deltas, alongside the collector's normal operation")
+	replayFlag := flag.String("replay", "", "path to a trace.jsonl previously written by --record; when set, it replaces --mode as the source of truth for AllMetrics")
+	faultsFlag := flag.String("faults", "", "path to a fault schedule YAML file scripting disk/raid/fuse failures on a timeline; only used in sim/hybrid mode")
+	flag.Parse()
+
+	diskIDs := strings.Split(*diskIDsFlag, ",")
+	raidIDs := strings.Split(*raidIDsFlag, ",")
+
+	workload, err := resolveScenario(*scenarioFlag)
+	if err != nil {
+		log.Fatalf("scenario error: %v", err)
+	}
+
+	faultSchedule, err := resolveFaultSchedule(*faultsFlag)
+	if err != nil {
+		log.Fatalf("fault schedule error: %v", err)
+	}
+
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
-	reg, allMetrics := metrics.NewMetricsRegistry()
-
-	diskIDs := []string{"disk0", "disk1", "disk2", "disk3"}
-	raidIDs := []string{"raid0", "raid1", "raid3"}
+	var reg *prometheus.Registry
+	var allMetrics *metrics.AllMetrics
+	if workload != nil {
+		reg, allMetrics = metrics.NewMetricsRegistryWithBuckets(workload.LatencyBuckets())
+	} else {
+		reg, allMetrics = metrics.NewMetricsRegistry()
+	}
 
-	sim := simulator.NewSimulator(allMetrics, diskIDs, raidIDs)
+	var collector simulator.Collector
+	if *replayFlag != "" {
+		collector = simulator.NewReplayer(allMetrics, *replayFlag)
+	} else {
+		collector, err = newCollector(*mode, allMetrics, diskIDs, raidIDs, workload, faultSchedule, *seedFlag)
+		if err != nil {
+			log.Fatalf("collector setup error: %v", err)
+		}
+	}
 
 	var wg sync.WaitGroup
-	sim.Start(ctx, &wg, 1*time.Second)
+	collector.Start(ctx, &wg, 1*time.Second)
+
+	if *recordFlag != "" {
+		recorder, err := simulator.NewRecorder(reg, *recordFlag)
+		if err != nil {
+			log.Fatalf("recorder setup error: %v", err)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer recorder.Close()
+
+			ticker := time.NewTicker(1 * time.Second)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					if err := recorder.RecordTick(); err != nil {
+						log.Printf("trace record error: %v", err)
+					}
+				}
+			}
+		}()
+	}
+
+	graphiteCfg := graphite.LoadConfigFromEnv()
+	if graphiteCfg.Enabled() {
+		bridge := graphite.NewBridge(graphiteCfg, reg)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			log.Printf("Pushing metrics to Graphite at %s every %s", graphiteCfg.URL, graphiteCfg.Interval)
+			bridge.Run(ctx)
+		}()
+	}
+
+	ingestSvc, walHandle, err := setupIngest(allMetrics)
+	if err != nil {
+		log.Fatalf("ingest setup error: %v", err)
+	}
+	if walHandle != nil {
+		defer func() {
+			if cerr := walHandle.Close(); cerr != nil {
+				log.Printf("WAL close error: %v", cerr)
+			}
+		}()
+	}
+
+	grpcCfg, err := server.LoadGRPCConfigFromEnv()
+	if err != nil {
+		log.Fatalf("gRPC config error: %v", err)
+	}
+
+	grpcSrv, err := server.NewGRPCServer(grpcCfg, ingestSvc, reg)
+	if err != nil {
+		log.Fatalf("gRPC server error: %v", err)
+	}
+
+	go func() {
+		log.Printf("Starting gRPC ingest server on %s", grpcCfg.UDSPath)
+
+		if err := grpcSrv.Serve(); err != nil {
+			log.Fatalf("gRPC server error: %v", err)
+		}
+	}()
 
 	addr := ":8080"
 	if port := os.Getenv("METRICS_PORT"); port != "" {
 		addr = ":" + port
 	}
 
-	srv := server.NewHTTPServer(addr, reg)
+	benchCfg, err := bench.LoadConfigFromEnv()
+	if err != nil {
+		log.Fatalf("bench config error: %v", err)
+	}
+	benchHandler := bench.NewHTTPHandler(bench.NewRunner(benchCfg), allMetrics.Bench)
+
+	onScrape := checkpointHook(walHandle)
+	srv := server.NewHTTPServer(addr, reg, onScrape, grpcSrv.Ready, benchHandler)
 
 	go func() {
 		log.Printf("Starting metrics server on %s", addr)
@@ -55,6 +175,132 @@ func main() {
 		log.Printf("HTTP server shutdown error: %v", err)
 	}
 
+	if err := grpcSrv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("gRPC server shutdown error: %v", err)
+	}
+
 	wg.Wait()
 	log.Println("Shutdown complete")
 }
+
+// newCollector builds the Collector selected by --mode: sim drives AllMetrics
+// with fabricated activity (optionally following workload instead of its
+// built-in random ranges), host reads it from the system (diskIDs/raidIDs
+// must then be real device names), and hybrid prefers host readings but
+// falls back to simulation per-subsystem where the host can't supply them.
+func newCollector(mode string, allMetrics *metrics.AllMetrics, diskIDs, raidIDs []string, workload *simulator.Workload, faultSchedule *faultinjector.FaultSchedule, seed int64) (simulator.Collector, error) {
+	newSim := func() *simulator.Simulator {
+		var sim *simulator.Simulator
+		if seed != 0 {
+			sim = simulator.NewSimulatorWithSeed(seed, allMetrics, diskIDs, raidIDs)
+		} else {
+			sim = simulator.NewSimulator(allMetrics, diskIDs, raidIDs)
+		}
+		if workload != nil {
+			sim.SetWorkload(workload)
+		}
+		if faultSchedule != nil {
+			sim.SetFaultSchedule(faultSchedule)
+		}
+		return sim
+	}
+
+	switch mode {
+	case "sim":
+		return newSim(), nil
+	case "host":
+		return simulator.NewHostCollector(allMetrics, diskIDs, raidIDs), nil
+	case "hybrid":
+		host := simulator.NewHostCollector(allMetrics, diskIDs, raidIDs)
+		return simulator.NewHybridCollector(host, newSim()), nil
+	default:
+		return nil, fmt.Errorf("unknown --mode %q: want sim, host, or hybrid", mode)
+	}
+}
+
+// resolveScenario resolves --scenario to a Workload: empty keeps the
+// Simulator's built-in random ranges, a name matching BuiltinWorkloads
+// selects that scenario, and anything else is treated as a path to a
+// scenario YAML/JSON file.
+func resolveScenario(scenario string) (*simulator.Workload, error) {
+	if scenario == "" {
+		return nil, nil
+	}
+	if w, ok := simulator.BuiltinWorkloads[scenario]; ok {
+		return w, nil
+	}
+	return simulator.LoadWorkload(scenario)
+}
+
+// resolveFaultSchedule resolves --faults to a FaultSchedule: empty disables
+// fault injection entirely, anything else is treated as a path to a fault
+// schedule YAML file.
+func resolveFaultSchedule(faults string) (*faultinjector.FaultSchedule, error) {
+	if faults == "" {
+		return nil, nil
+	}
+	return faultinjector.LoadFaultSchedule(faults)
+}
+
+// setupIngest builds the gRPC ingest service. If a WAL directory is
+// configured, it opens the WAL, replays any segments left over from a prior
+// run through the service's apply path, and attaches the WAL so future
+// pushes are durably logged before being applied.
+func setupIngest(allMetrics *metrics.AllMetrics) (*ingest.Service, *wal.WAL, error) {
+	walCfg := wal.LoadConfigFromEnv()
+	if !walCfg.Enabled() {
+		return ingest.NewService(allMetrics), nil, nil
+	}
+
+	w, err := wal.Open(walCfg, allMetrics.Wal)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open wal: %w", err)
+	}
+
+	svc := ingest.NewServiceWithWAL(allMetrics, w)
+
+	cp, err := wal.LoadCheckpoint(walCfg.Dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("load wal checkpoint: %w", err)
+	}
+
+	newCP, err := wal.Replay(walCfg.Dir, cp, svc.Apply)
+	if err != nil {
+		return nil, nil, fmt.Errorf("replay wal: %w", err)
+	}
+	if err := wal.SaveCheckpoint(walCfg.Dir, newCP); err != nil {
+		return nil, nil, fmt.Errorf("save wal checkpoint: %w", err)
+	}
+
+	return svc, w, nil
+}
+
+// checkpointHook returns the function the /metrics scrape handler calls
+// immediately before each Gather, which snapshots the WAL's current write
+// offset and returns a second function to save it as the checkpoint once
+// that scrape completes. Snapshotting before Gather (rather than after)
+// matters: a batch appended+applied in the window between Gather and the
+// post-scrape call is not reflected in the scrape that just happened, so
+// checkpointing past it would mean a crash in that window drops state that
+// was never actually exported. Once the checkpoint is saved, it also prunes
+// segments the checkpoint now covers once the WAL exceeds MaxTotalBytes, so
+// a long-running process enforces that bound instead of only Replay's
+// startup truncation.
+func checkpointHook(w *wal.WAL) func() func() {
+	if w == nil {
+		return nil
+	}
+
+	return func() func() {
+		pos := w.Position()
+		return func() {
+			if err := wal.SaveCheckpoint(w.Dir(), pos); err != nil {
+				log.Printf("WAL checkpoint save error: %v", err)
+				return
+			}
+			if err := w.PruneThrough(pos); err != nil {
+				log.Printf("WAL prune error: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// serverKiller periodically SIGKILLs and respawns a child gateway process,
+// to exercise the WAL-replay crash-restart path (see wal.Replay in
+// cmd/metrics-gateway) under concurrent load rather than only between test
+// runs.
+type serverKiller struct {
+	bin      string
+	args     []string
+	interval time.Duration
+}
+
+func newServerKiller(bin string, args []string, interval time.Duration) *serverKiller {
+	return &serverKiller{bin: bin, args: args, interval: interval}
+}
+
+// run spawns the gateway, kills it every interval, and respawns it, until
+// ctx is canceled. It never returns an error: a failed spawn is logged and
+// retried on the next tick, since the point of this mode is to keep hammering
+// the restart path for the duration of the stresspush run.
+func (k *serverKiller) run(ctx context.Context) {
+	var cmd *exec.Cmd
+
+	spawn := func() {
+		cmd = exec.Command(k.bin, k.args...)
+		cmd.Stdout = nil
+		cmd.Stderr = nil
+		if err := cmd.Start(); err != nil {
+			log.Printf("stresspush: failed to spawn gateway %q: %v", k.bin, err)
+			cmd = nil
+			return
+		}
+		log.Printf("stresspush: spawned gateway pid %d", cmd.Process.Pid)
+	}
+
+	kill := func() {
+		if cmd == nil || cmd.Process == nil {
+			return
+		}
+		log.Printf("stresspush: killing gateway pid %d", cmd.Process.Pid)
+		_ = cmd.Process.Signal(syscall.SIGKILL)
+		_ = cmd.Wait()
+		cmd = nil
+	}
+
+	spawn()
+	ticker := time.NewTicker(k.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			kill()
+			return
+		case <-ticker.C:
+			kill()
+			spawn()
+		}
+	}
+}
@@ -0,0 +1,212 @@
+// Command stresspush is a long-running soak harness for the metrics-gateway
+// ingest path, modeled on classic leveldb dbstress-style tools: it opens N
+// concurrent gRPC client-streaming connections to Service.Push and keeps
+// feeding them synthetic MetricsBatch traffic (with a configurable mix of
+// disk/raid/fuse ops, latency distribution, and injected faults/NaNs) for a
+// fixed duration, then compares its own locally-predicted accept/reject
+// counts against what the server actually reported and exits non-zero if
+// they drift beyond tolerance.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	_ "net/http/pprof"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	pb "metrics-gateway/internal/pb/metrics/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+func main() {
+	addr := flag.String("addr", "unix:///sockets/metrics-gateway.sock", "gRPC target to dial (unix:///path or host:port)")
+	token := flag.String("token", "", "bearer token sent as x-metrics-token, if the server requires auth")
+	clients := flag.Int("clients", 8, "number of concurrent gRPC clients")
+	rate := flag.Float64("rate", 50, "ops/sec/client")
+	disks := flag.Int("disks", 4, "number of distinct disk ids to generate ops for")
+	raids := flag.Int("raids", 3, "number of distinct raid ids to generate ops for")
+	opMixFlag := flag.String("op-mix", "read=5,write=3,fsync=1", "comma-separated op weights, e.g. read=5,write=3,fsync=1")
+	latencyDistFlag := flag.String("latency-dist", "lognormal:-9,1", "kind:params, e.g. uniform:0.0001,0.01 or lognormal:-9,1")
+	faultProb := flag.Float64("fault-prob", 0.01, "fraction of ops that set Error=true or an invalid id, to exercise the reject path")
+	nanProb := flag.Float64("nan-prob", 0.001, "fraction of ops with a NaN/Inf latency, also rejected")
+	duration := flag.Duration("duration", 10*time.Minute, "how long to run before reporting and exiting")
+	tolerance := flag.Uint64("drift-tolerance", 0, "maximum allowed absolute drift between predicted and server-reported accepted samples")
+	pprofAddr := flag.String("pprof-addr", ":6061", "address for this harness's own pprof endpoint (empty disables it)")
+	killServerEvery := flag.Duration("kill-server-every", 0, "if set, spawn a child gateway and SIGKILL+respawn it on this interval")
+	serverBin := flag.String("server-bin", "", "path to the metrics-gateway binary, required with --kill-server-every")
+	serverArgs := flag.String("server-args", "", "space-separated extra args passed to --server-bin")
+	seed := flag.Int64("seed", time.Now().UnixNano(), "PRNG seed, for reproducing a specific run")
+	flag.Parse()
+
+	mix, err := parseOpMix(*opMixFlag)
+	if err != nil {
+		log.Fatalf("stresspush: %v", err)
+	}
+	dist, err := parseLatencyDist(*latencyDistFlag)
+	if err != nil {
+		log.Fatalf("stresspush: %v", err)
+	}
+	if *killServerEvery > 0 && *serverBin == "" {
+		log.Fatalf("stresspush: --server-bin is required with --kill-server-every")
+	}
+
+	if *pprofAddr != "" {
+		go func() {
+			log.Printf("stresspush: pprof listening on %s", *pprofAddr)
+			if err := http.ListenAndServe(*pprofAddr, nil); err != nil {
+				log.Printf("stresspush: pprof server error: %v", err)
+			}
+		}()
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	if *killServerEvery > 0 {
+		var args []string
+		if *serverArgs != "" {
+			args = strings.Fields(*serverArgs)
+		}
+		killer := newServerKiller(*serverBin, args, *killServerEvery)
+		go killer.run(ctx)
+		// Give the first spawn a moment to bind its listener before the
+		// clients below start dialing it.
+		time.Sleep(500 * time.Millisecond)
+	}
+
+	runCtx, runCancel := context.WithTimeout(ctx, *duration)
+	defer runCancel()
+
+	w := workload{
+		diskIDs:  genIDs("disk", *disks),
+		raidIDs:  genIDs("raid", *raids),
+		mix:      mix,
+		dist:     dist,
+		faultP:   *faultProb,
+		nanP:     *nanProb,
+		sizeBias: 1 << 16,
+	}
+
+	st := &stats{}
+
+	var wg sync.WaitGroup
+	for i := 0; i < *clients; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			if err := runClient(runCtx, id, *addr, *token, *rate, *seed+int64(id), w, st); err != nil {
+				log.Printf("stresspush: client %d: %v", id, err)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	localAccepted, localRejected, serverAccepted, serverRejected := st.snapshot()
+	drift := st.drift()
+	log.Printf(
+		"stresspush: done. local accepted=%d rejected=%d, server accepted=%d rejected=%d, drift=%d (tolerance=%d)",
+		localAccepted, localRejected, serverAccepted, serverRejected, drift, *tolerance,
+	)
+
+	if drift > *tolerance {
+		fmt.Fprintf(os.Stderr, "stresspush: accepted-sample drift %d exceeds tolerance %d\n", drift, *tolerance)
+		os.Exit(1)
+	}
+}
+
+// reconnectBackoff is how long runClient waits before redialing after a
+// stream dies mid-run, giving a --kill-server-every restart time to rebind
+// its listener instead of hot-looping failed dials against a dead server.
+const reconnectBackoff = 200 * time.Millisecond
+
+// runClient keeps one client-streaming Push RPC open at a time against addr,
+// sending batches at rate ops/sec, until ctx is done. If the stream dies
+// mid-run (e.g. a --kill-server-every restart), it reconnects and opens a
+// fresh stream rather than abandoning the client for the rest of the run, so
+// the server-reported tally st accumulates still reflects nearly all of this
+// client's traffic instead of permanently losing everything after the first
+// restart.
+func runClient(ctx context.Context, id int, addr, token string, rate float64, seed int64, w workload, st *stats) error {
+	rng := rand.New(rand.NewSource(seed))
+	sourceID := fmt.Sprintf("stresspush-%d", id)
+
+	interval := time.Second
+	if rate > 0 {
+		interval = time.Duration(float64(time.Second) / rate)
+	}
+
+	for ctx.Err() == nil {
+		if err := runClientStream(ctx, addr, token, sourceID, interval, rng, w, st); err != nil && ctx.Err() == nil {
+			log.Printf("stresspush: client %d: stream error, reconnecting: %v", id, err)
+			time.Sleep(reconnectBackoff)
+		}
+	}
+	return nil
+}
+
+// runClientStream dials addr, opens a single Push stream, and sends batches
+// on it until ctx is done or the stream fails. Either way it tries to fold a
+// final PushResponse into st: cleanly via CloseAndRecv when ctx ends the
+// stream, or, on a Send failure, by still attempting CloseAndRecv in case the
+// server managed to reply before going away, so a mid-stream restart loses
+// as little of that stream's server-side tally as possible.
+func runClientStream(ctx context.Context, addr, token, sourceID string, interval time.Duration, rng *rand.Rand, w workload, st *stats) error {
+	cc, err := dial(addr)
+	if err != nil {
+		return fmt.Errorf("dial: %w", err)
+	}
+	defer cc.Close()
+
+	client := pb.NewMetricsIngestorClient(cc)
+
+	rpcCtx := ctx
+	if token != "" {
+		rpcCtx = metadata.AppendToOutgoingContext(ctx, "x-metrics-token", token)
+	}
+
+	stream, err := client.Push(rpcCtx)
+	if err != nil {
+		return fmt.Errorf("open push stream: %w", err)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			resp, err := stream.CloseAndRecv()
+			if err != nil {
+				return fmt.Errorf("close stream: %w", err)
+			}
+			st.recordServer(resp.GetAcceptedSamples(), resp.GetRejectedSamples())
+			return nil
+		case <-ticker.C:
+			batch, accepted := w.genBatch(rng, sourceID)
+			if err := stream.Send(batch); err != nil {
+				if resp, rerr := stream.CloseAndRecv(); rerr == nil {
+					st.recordServer(resp.GetAcceptedSamples(), resp.GetRejectedSamples())
+				}
+				return fmt.Errorf("send: %w", err)
+			}
+			st.recordLocal(accepted)
+		}
+	}
+}
+
+func dial(addr string) (*grpc.ClientConn, error) {
+	return grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+}
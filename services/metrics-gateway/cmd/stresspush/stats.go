@@ -0,0 +1,48 @@
+package main
+
+import "sync/atomic"
+
+// stats accumulates the locally-predicted accept/reject counts (computed
+// from the fault/nan injection decisions stresspush itself made) alongside
+// the server's own tally from each client's final PushResponse, so a run
+// can detect whether the gateway silently dropped or double-counted
+// samples across a crash-restart (see --kill-server-every).
+type stats struct {
+	localAccepted uint64
+	localRejected uint64
+
+	serverAccepted uint64
+	serverRejected uint64
+}
+
+func (s *stats) recordLocal(accepted bool) {
+	if accepted {
+		atomic.AddUint64(&s.localAccepted, 1)
+	} else {
+		atomic.AddUint64(&s.localRejected, 1)
+	}
+}
+
+func (s *stats) recordServer(accepted, rejected uint64) {
+	atomic.AddUint64(&s.serverAccepted, accepted)
+	atomic.AddUint64(&s.serverRejected, rejected)
+}
+
+// drift reports the absolute difference between what stresspush expected
+// the server to accept and what the server actually reported, which is the
+// signal --kill-server-every is meant to catch growing.
+func (s *stats) drift() uint64 {
+	local := atomic.LoadUint64(&s.localAccepted)
+	server := atomic.LoadUint64(&s.serverAccepted)
+	if local > server {
+		return local - server
+	}
+	return server - local
+}
+
+func (s *stats) snapshot() (localAccepted, localRejected, serverAccepted, serverRejected uint64) {
+	return atomic.LoadUint64(&s.localAccepted),
+		atomic.LoadUint64(&s.localRejected),
+		atomic.LoadUint64(&s.serverAccepted),
+		atomic.LoadUint64(&s.serverRejected)
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	pb "metrics-gateway/internal/pb/metrics/v1"
+)
+
+// opMix holds the relative weights of the three op kinds a worker generates
+// on each tick. Weights need not sum to 1; pick normalizes them.
+type opMix struct {
+	read  float64
+	write float64
+	fsync float64
+}
+
+// parseOpMix parses "read=5,write=3,fsync=1" into an opMix. Missing keys
+// default to 0, so "read=1,write=1" is a valid read/write-only mix.
+func parseOpMix(s string) (opMix, error) {
+	mix := opMix{read: 1, write: 1, fsync: 0}
+	if strings.TrimSpace(s) == "" {
+		return mix, nil
+	}
+
+	mix = opMix{}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return opMix{}, fmt.Errorf("invalid --op-mix entry %q", part)
+		}
+		w, err := strconv.ParseFloat(strings.TrimSpace(kv[1]), 64)
+		if err != nil {
+			return opMix{}, fmt.Errorf("invalid --op-mix weight in %q: %w", part, err)
+		}
+		switch strings.TrimSpace(kv[0]) {
+		case "read":
+			mix.read = w
+		case "write":
+			mix.write = w
+		case "fsync":
+			mix.fsync = w
+		default:
+			return opMix{}, fmt.Errorf("unknown --op-mix key %q", kv[0])
+		}
+	}
+
+	if mix.read+mix.write+mix.fsync <= 0 {
+		return opMix{}, fmt.Errorf("--op-mix weights must sum to a positive number")
+	}
+	return mix, nil
+}
+
+type opKind int
+
+const (
+	opKindRead opKind = iota
+	opKindWrite
+	opKindFsync
+)
+
+func (m opMix) pick(rng *rand.Rand) opKind {
+	total := m.read + m.write + m.fsync
+	r := rng.Float64() * total
+	if r < m.read {
+		return opKindRead
+	}
+	if r < m.read+m.write {
+		return opKindWrite
+	}
+	return opKindFsync
+}
+
+// latencyDist samples a synthetic latency in seconds for a generated op.
+type latencyDist interface {
+	sample(rng *rand.Rand) float64
+}
+
+type uniformDist struct {
+	min, max float64
+}
+
+func (d uniformDist) sample(rng *rand.Rand) float64 {
+	return d.min + rng.Float64()*(d.max-d.min)
+}
+
+type lognormalDist struct {
+	mu, sigma float64
+}
+
+func (d lognormalDist) sample(rng *rand.Rand) float64 {
+	return math.Exp(d.mu + d.sigma*rng.NormFloat64())
+}
+
+// parseLatencyDist parses "uniform:0.0001,0.01" or "lognormal:-9,1".
+func parseLatencyDist(s string) (latencyDist, error) {
+	if strings.TrimSpace(s) == "" {
+		return uniformDist{min: 0.0001, max: 0.01}, nil
+	}
+
+	kind, paramsStr, ok := strings.Cut(s, ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid --latency-dist %q, want kind:params", s)
+	}
+
+	params := strings.Split(paramsStr, ",")
+	if len(params) != 2 {
+		return nil, fmt.Errorf("invalid --latency-dist params %q, want two comma-separated numbers", paramsStr)
+	}
+	a, err := strconv.ParseFloat(strings.TrimSpace(params[0]), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --latency-dist first param: %w", err)
+	}
+	b, err := strconv.ParseFloat(strings.TrimSpace(params[1]), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --latency-dist second param: %w", err)
+	}
+
+	switch strings.TrimSpace(kind) {
+	case "uniform":
+		return uniformDist{min: a, max: b}, nil
+	case "lognormal":
+		return lognormalDist{mu: a, sigma: b}, nil
+	default:
+		return nil, fmt.Errorf("unknown --latency-dist kind %q", kind)
+	}
+}
+
+// workload generates synthetic batches for one client, deciding per-op
+// whether to inject a fault (Error=true, which the server counts as an op
+// error but still accepts, plus for about half of faulted disk/raid ops an
+// empty id, which handleDiskOps/handleRaidOps reject) or a NaN/Inf latency
+// (rejected by validLatency), so a stresspush run exercises both the accept
+// and reject paths the unit tests in chunk2-1/chunk1-* only cover sample by
+// sample.
+type workload struct {
+	diskIDs  []string
+	raidIDs  []string
+	mix      opMix
+	dist     latencyDist
+	faultP   float64
+	nanP     float64
+	sizeBias int64
+}
+
+// genBatch builds a single-op MetricsBatch for sourceID and reports whether
+// the op it generated is expected to be accepted by the server, so the
+// caller can keep a running tally to compare against PushResponse.
+func (w workload) genBatch(rng *rand.Rand, sourceID string) (*pb.MetricsBatch, bool) {
+	kind := w.mix.pick(rng)
+	latency := w.dist.sample(rng)
+	faulted := rng.Float64() < w.faultP
+	nanned := rng.Float64() < w.nanP
+
+	if nanned {
+		if rng.Intn(2) == 0 {
+			latency = math.NaN()
+		} else {
+			latency = math.Inf(1)
+		}
+	}
+
+	bytes := uint64(64 + rng.Int63n(w.sizeBias+1))
+	batch := &pb.MetricsBatch{SourceId: sourceID}
+
+	// Roughly 2/3 of ticks hit a disk, 1/3 a raid, regardless of op kind,
+	// so both handleDiskOps and handleRaidOps see steady traffic.
+	onDisk := rng.Intn(3) != 0
+
+	switch kind {
+	case opKindFsync:
+		batch.FuseOps = []*pb.FuseOp{{
+			Op:             pb.FuseOpType_FUSE_OP_FSYNC,
+			LatencySeconds: latency,
+			Error:          faulted,
+		}}
+		return batch, !nanned
+	}
+
+	ioOp := pb.IoOpType_IO_OP_READ
+	if kind == opKindWrite {
+		ioOp = pb.IoOpType_IO_OP_WRITE
+	}
+
+	if onDisk {
+		diskID := pickID(rng, w.diskIDs)
+		if faulted && rng.Intn(2) == 0 {
+			diskID = "" // invalid id: rejected by validateDiskOp
+		}
+		batch.DiskOps = []*pb.DiskOp{{
+			DiskId:         diskID,
+			Op:             ioOp,
+			Bytes:          bytes,
+			LatencySeconds: latency,
+			Error:          faulted,
+		}}
+		accepted := !nanned && diskID != ""
+		return batch, accepted
+	}
+
+	raidID := pickID(rng, w.raidIDs)
+	if faulted && rng.Intn(2) == 0 {
+		raidID = ""
+	}
+	batch.RaidOps = []*pb.RaidOp{{
+		RaidId:         raidID,
+		Op:             ioOp,
+		Bytes:          bytes,
+		LatencySeconds: latency,
+	}}
+	accepted := !nanned && raidID != ""
+	return batch, accepted
+}
+
+func pickID(rng *rand.Rand, ids []string) string {
+	if len(ids) == 0 {
+		return ""
+	}
+	return ids[rng.Intn(len(ids))]
+}
+
+func genIDs(prefix string, n int) []string {
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("%s%d", prefix, i)
+	}
+	return ids
+}
@@ -0,0 +1,118 @@
+package bench
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadReferenceConstantsDefaultsWithoutFile(t *testing.T) {
+	refs, err := LoadReferenceConstants("")
+	if err != nil {
+		t.Fatalf("LoadReferenceConstants: %v", err)
+	}
+	if refs != defaultReferenceConstants {
+		t.Fatalf("expected default reference constants, got %+v", refs)
+	}
+}
+
+func TestLoadReferenceConstantsParsesFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "refs.yaml")
+	content := "disk_throughput_mbps: 500\nraid_throughput_mbps: 350\nfuse_ops_per_second: 25000\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write refs file: %v", err)
+	}
+
+	refs, err := LoadReferenceConstants(path)
+	if err != nil {
+		t.Fatalf("LoadReferenceConstants: %v", err)
+	}
+	if refs.DiskThroughputMBps != 500 || refs.RaidThroughputMBps != 350 || refs.FuseOpsPerSecond != 25000 {
+		t.Fatalf("unexpected reference constants: %+v", refs)
+	}
+}
+
+func TestLoadReferenceConstantsMissingFile(t *testing.T) {
+	if _, err := LoadReferenceConstants("/nonexistent/refs.yaml"); err == nil {
+		t.Fatal("expected an error for a missing reference file")
+	}
+}
+
+func TestScore(t *testing.T) {
+	if got := score(400, 400); got != 1 {
+		t.Fatalf("score(400, 400) = %v, want 1", got)
+	}
+	if got := score(200, 400); got != 0.5 {
+		t.Fatalf("score(200, 400) = %v, want 0.5", got)
+	}
+	if got := score(100, 0); got != 0 {
+		t.Fatalf("score with zero reference = %v, want 0", got)
+	}
+}
+
+func TestRunnerRunProducesScoresAgainstTempPaths(t *testing.T) {
+	dir := t.TempDir()
+
+	cfg := Config{
+		DiskPath:        dir,
+		FusePath:        dir,
+		WallClockBudget: 20 * time.Millisecond,
+		References:      defaultReferenceConstants,
+	}
+	r := NewRunner(cfg)
+
+	scores, err := r.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if scores.Fuse <= 0 {
+		t.Fatalf("expected a positive fuse score, got %v", scores.Fuse)
+	}
+	if scores.Aggregate != (scores.Disk+scores.Raid+scores.Fuse)/3 {
+		t.Fatalf("aggregate %v does not match mean of disk/raid/fuse", scores.Aggregate)
+	}
+}
+
+func TestRunnerRunRejectsConcurrentRuns(t *testing.T) {
+	dir := t.TempDir()
+	cfg := Config{
+		DiskPath:        dir,
+		FusePath:        dir,
+		WallClockBudget: 50 * time.Millisecond,
+		References:      defaultReferenceConstants,
+	}
+	r := NewRunner(cfg)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.Run(context.Background()); err == nil {
+		t.Fatal("expected an error when a run is already in progress")
+	}
+}
+
+func TestMedianOfThreeDampsOutlier(t *testing.T) {
+	r := NewRunner(Config{})
+
+	durations := []time.Duration{10 * time.Millisecond, 10 * time.Millisecond, 500 * time.Millisecond}
+	i := 0
+	result, err := r.medianOfThree(func() (workloadResult, error) {
+		res := workloadResult{bytesMoved: int64(i + 1) * stripeSize, wall: durations[i]}
+		i++
+		return res, nil
+	})
+	if err != nil {
+		t.Fatalf("medianOfThree: %v", err)
+	}
+
+	// Sorted by throughput, the slow (i=2) iteration is lowest despite
+	// moving the most bytes, and the fast i=1 iteration is highest; the
+	// median is the fast i=0 iteration.
+	if result.bytesMoved != stripeSize {
+		t.Fatalf("expected the median iteration's result, got bytesMoved=%d", result.bytesMoved)
+	}
+}
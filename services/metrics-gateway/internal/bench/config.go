@@ -0,0 +1,128 @@
+// Package bench runs a small suite of reference I/O workloads against the
+// locally-mounted disk/raid/fuse paths and scores their measured
+// throughput/latency against calibrated reference constants, producing a
+// unit-free score an operator can compare across differently-sized
+// simulator hosts instead of eyeballing raw counters.
+package bench
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ReferenceConstants are the calibrated baseline measurements a score of
+// 1.0 represents for each workload. They're deployment-specific: an
+// operator measures them once on a reference host and ships the resulting
+// file alongside the gateway (BENCH_REFERENCE_FILE) so scores stay
+// comparable across every other deployment.
+type ReferenceConstants struct {
+	DiskThroughputMBps float64 `yaml:"disk_throughput_mbps"`
+	RaidThroughputMBps float64 `yaml:"raid_throughput_mbps"`
+	FuseOpsPerSecond   float64 `yaml:"fuse_ops_per_second"`
+}
+
+// defaultReferenceConstants are conservative numbers for a modest SATA SSD,
+// used until an operator supplies a calibrated BENCH_REFERENCE_FILE.
+var defaultReferenceConstants = ReferenceConstants{
+	DiskThroughputMBps: 400,
+	RaidThroughputMBps: 300,
+	FuseOpsPerSecond:   20000,
+}
+
+// LoadReferenceConstants reads a calibrated reference file (YAML or JSON).
+// An empty path returns defaultReferenceConstants unchanged.
+func LoadReferenceConstants(path string) (ReferenceConstants, error) {
+	if path == "" {
+		return defaultReferenceConstants, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ReferenceConstants{}, fmt.Errorf("bench: read reference file %s: %w", path, err)
+	}
+
+	refs := defaultReferenceConstants
+	if err := yaml.Unmarshal(data, &refs); err != nil {
+		return ReferenceConstants{}, fmt.Errorf("bench: parse reference file %s: %w", path, err)
+	}
+	return refs, nil
+}
+
+// Config configures a Runner.
+type Config struct {
+	DiskPath  string
+	RaidPaths []string
+	FusePath  string
+
+	// WallClockBudget is how long each of the three median-of-three
+	// iterations of a single workload runs for.
+	WallClockBudget time.Duration
+
+	// NoiseFactor bounds how far a workload's wall-clock time may exceed
+	// its own process CPU time before Run aborts with an error; a large
+	// gap means something else on the host was stealing cycles mid-run
+	// and the resulting score wouldn't be trustworthy. Zero disables the
+	// check.
+	NoiseFactor float64
+
+	References ReferenceConstants
+}
+
+// LoadConfigFromEnv builds a Config from BENCH_* environment variables.
+func LoadConfigFromEnv() (Config, error) {
+	refs, err := LoadReferenceConstants(os.Getenv("BENCH_REFERENCE_FILE"))
+	if err != nil {
+		return Config{}, err
+	}
+
+	return Config{
+		DiskPath:        getenv("BENCH_DISK_PATH", os.TempDir()),
+		RaidPaths:       parsePathList(os.Getenv("BENCH_RAID_PATHS")),
+		FusePath:        getenv("BENCH_FUSE_PATH", os.TempDir()),
+		WallClockBudget: parseDurationMS(getenv("BENCH_WALL_CLOCK_BUDGET_MS", "1000"), 1*time.Second),
+		NoiseFactor:     parseFloat(getenv("BENCH_NOISE_FACTOR", "3"), 3),
+		References:      refs,
+	}, nil
+}
+
+func parsePathList(s string) []string {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	var out []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func getenv(k, def string) string {
+	v := os.Getenv(k)
+	if v == "" {
+		return def
+	}
+	return v
+}
+
+func parseFloat(s string, def float64) float64 {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return def
+	}
+	return f
+}
+
+func parseDurationMS(s string, def time.Duration) time.Duration {
+	ms, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || ms < 0 {
+		return def
+	}
+	return time.Duration(ms) * time.Millisecond
+}
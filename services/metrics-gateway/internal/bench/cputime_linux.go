@@ -0,0 +1,17 @@
+//go:build linux
+
+package bench
+
+import "syscall"
+
+// cpuTimeNow returns this process's total (user+system) CPU time in
+// seconds, used by Runner.timedRun to detect a noisy host.
+func cpuTimeNow() float64 {
+	var ru syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &ru); err != nil {
+		return 0
+	}
+	user := float64(ru.Utime.Sec) + float64(ru.Utime.Usec)/1e6
+	sys := float64(ru.Stime.Sec) + float64(ru.Stime.Usec)/1e6
+	return user + sys
+}
@@ -0,0 +1,56 @@
+package bench
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"metrics-gateway/internal/metrics"
+)
+
+// NewHTTPHandler returns the /bench handler: triggering a Runner.Run,
+// updating m's gauges with the result, and responding with the scores as
+// JSON. A run already in progress (see Runner.Run) is reported as 503
+// rather than queued, since a concurrent run would invalidate both.
+func NewHTTPHandler(r *Runner, m *metrics.BenchMetrics) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		scores, err := r.Run(req.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+
+		m.Disk.Set(scores.Disk)
+		m.Raid.Set(scores.Raid)
+		m.Fuse.Set(scores.Fuse)
+		m.Aggregate.Set(scores.Aggregate)
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(benchResponse{
+			Disk:      scores.Disk,
+			Raid:      scores.Raid,
+			Fuse:      scores.Fuse,
+			Aggregate: scores.Aggregate,
+			Samples: benchSamples{
+				Throughput: scores.Samples.ThroughputMBps,
+				P50:        scores.Samples.P50.Seconds(),
+				P95:        scores.Samples.P95.Seconds(),
+				P99:        scores.Samples.P99.Seconds(),
+			},
+		})
+	})
+}
+
+type benchResponse struct {
+	Disk      float64      `json:"disk"`
+	Raid      float64      `json:"raid"`
+	Fuse      float64      `json:"fuse"`
+	Aggregate float64      `json:"aggregate"`
+	Samples   benchSamples `json:"samples"`
+}
+
+type benchSamples struct {
+	Throughput float64 `json:"throughput"`
+	P50        float64 `json:"p50"`
+	P95        float64 `json:"p95"`
+	P99        float64 `json:"p99"`
+}
@@ -0,0 +1,22 @@
+//go:build linux
+
+package bench
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// openDirect opens path with O_DIRECT, bypassing the page cache so the
+// disk/raid workloads' measured throughput reflects the device rather than
+// memory bandwidth. Some filesystems (tmpfs, overlayfs) reject O_DIRECT
+// outright; falling back to a buffered open there keeps the benchmark
+// runnable, at the cost of a less meaningful score on those paths.
+func openDirect(path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC|unix.O_DIRECT, 0o600)
+	if err != nil {
+		return os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o600)
+	}
+	return f, nil
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+package bench
+
+import "os"
+
+// openDirect falls back to a buffered open on platforms without an O_DIRECT
+// implementation in this package.
+func openDirect(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o600)
+}
@@ -0,0 +1,145 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Scores holds the unit-free score (1.0 == reference constant) for each
+// subsystem, plus the aggregate (their mean), alongside the disk workload's
+// raw throughput/latency samples.
+type Scores struct {
+	Disk      float64
+	Raid      float64
+	Fuse      float64
+	Aggregate float64
+
+	Samples Samples
+}
+
+// Samples is the disk workload's raw measurement, the subsystem an operator
+// most often wants the underlying numbers for rather than just its score.
+type Samples struct {
+	ThroughputMBps float64
+	P50            time.Duration
+	P95            time.Duration
+	P99            time.Duration
+}
+
+// Runner executes the disk/raid/fuse reference workloads and scores them
+// against Config.References. Run is guarded by a mutex: only one benchmark
+// may execute at a time, since concurrent runs would contend for the same
+// disk and CPU and invalidate each other's measurements.
+type Runner struct {
+	cfg Config
+	mu  sync.Mutex
+}
+
+func NewRunner(cfg Config) *Runner {
+	return &Runner{cfg: cfg}
+}
+
+// Run executes all three workloads and returns their scores. It returns an
+// error without running anything if another Run is already in progress.
+func (r *Runner) Run(ctx context.Context) (Scores, error) {
+	if !r.mu.TryLock() {
+		return Scores{}, fmt.Errorf("bench: a run is already in progress")
+	}
+	defer r.mu.Unlock()
+
+	diskResult, err := r.medianOfThree(func() (workloadResult, error) {
+		return diskWorkload(r.cfg.DiskPath, r.cfg.WallClockBudget)
+	})
+	if err != nil {
+		return Scores{}, fmt.Errorf("bench: disk workload: %w", err)
+	}
+
+	raidPaths := r.cfg.RaidPaths
+	if len(raidPaths) == 0 {
+		raidPaths = []string{r.cfg.DiskPath, r.cfg.DiskPath, r.cfg.DiskPath}
+	}
+	raidResult, err := r.medianOfThree(func() (workloadResult, error) {
+		return raidWorkload(raidPaths, r.cfg.WallClockBudget)
+	})
+	if err != nil {
+		return Scores{}, fmt.Errorf("bench: raid workload: %w", err)
+	}
+
+	fuseResult, err := r.medianOfThree(func() (workloadResult, error) {
+		return fuseWorkload(r.cfg.FusePath, r.cfg.WallClockBudget)
+	})
+	if err != nil {
+		return Scores{}, fmt.Errorf("bench: fuse workload: %w", err)
+	}
+
+	diskScore := score(diskResult.throughputMBps(), r.cfg.References.DiskThroughputMBps)
+	raidScore := score(raidResult.throughputMBps(), r.cfg.References.RaidThroughputMBps)
+	fuseScore := score(fuseResult.opsPerSecond(), r.cfg.References.FuseOpsPerSecond)
+
+	return Scores{
+		Disk:      diskScore,
+		Raid:      raidScore,
+		Fuse:      fuseScore,
+		Aggregate: (diskScore + raidScore + fuseScore) / 3,
+		Samples: Samples{
+			ThroughputMBps: diskResult.throughputMBps(),
+			P50:            diskResult.percentile(0.50),
+			P95:            diskResult.percentile(0.95),
+			P99:            diskResult.percentile(0.99),
+		},
+	}, nil
+}
+
+// score normalizes measured against reference into a unit-free ratio. A
+// zero or negative reference means no baseline was configured, which is
+// reported as 0 rather than producing Inf/NaN.
+func score(measured, reference float64) float64 {
+	if reference <= 0 {
+		return 0
+	}
+	return measured / reference
+}
+
+// medianOfThree runs workload three times, guarded by the noise-factor
+// check on each iteration, and keeps the run whose throughput is the median
+// of the three, damping outliers from a single noisy iteration (a GC
+// pause, a neighboring process, etc).
+func (r *Runner) medianOfThree(workload func() (workloadResult, error)) (workloadResult, error) {
+	results := make([]workloadResult, 3)
+	for i := range results {
+		res, err := r.timedRun(workload)
+		if err != nil {
+			return workloadResult{}, err
+		}
+		results[i] = res
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].throughputMBps() < results[j].throughputMBps()
+	})
+	return results[1], nil
+}
+
+// timedRun wraps a single workload iteration with the noise-factor guard:
+// if its wall-clock time exceeds this process's own CPU time by more than
+// cfg.NoiseFactor, something else on the host stole cycles mid-run and the
+// resulting score isn't trustworthy enough to report.
+func (r *Runner) timedRun(workload func() (workloadResult, error)) (workloadResult, error) {
+	cpuBefore := cpuTimeNow()
+	result, err := workload()
+	if err != nil {
+		return workloadResult{}, err
+	}
+	cpuElapsed := cpuTimeNow() - cpuBefore
+
+	if r.cfg.NoiseFactor > 0 && cpuElapsed > 0 && result.wall.Seconds() > cpuElapsed*r.cfg.NoiseFactor {
+		return workloadResult{}, fmt.Errorf(
+			"wall-clock time (%s) exceeded CPU time (%.3fs) by more than %.1fx: host is too noisy for a reliable score",
+			result.wall, cpuElapsed, r.cfg.NoiseFactor,
+		)
+	}
+	return result, nil
+}
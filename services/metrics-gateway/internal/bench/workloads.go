@@ -0,0 +1,156 @@
+package bench
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// stripeSize is the chunk size the disk and raid workloads write in, chosen
+// to match the 64 KiB stripe width the RAID simulator's own metrics model.
+const stripeSize = 64 * 1024
+
+// workloadResult is one iteration's raw measurements.
+type workloadResult struct {
+	bytesMoved int64
+	ops        int64
+	latencies  []time.Duration
+	wall       time.Duration
+}
+
+func (r workloadResult) throughputMBps() float64 {
+	if r.wall <= 0 {
+		return 0
+	}
+	return float64(r.bytesMoved) / (1024 * 1024) / r.wall.Seconds()
+}
+
+func (r workloadResult) opsPerSecond() float64 {
+	if r.wall <= 0 {
+		return 0
+	}
+	return float64(r.ops) / r.wall.Seconds()
+}
+
+func (r workloadResult) percentile(p float64) time.Duration {
+	if len(r.latencies) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), r.latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	idx := int(p * float64(len(sorted)-1))
+	return sorted[idx]
+}
+
+// diskWorkload sequentially writes a single file in stripeSize chunks for
+// budget, opening it O_DIRECT where the platform supports it so the
+// kernel page cache doesn't mask the disk's real throughput.
+func diskWorkload(path string, budget time.Duration) (workloadResult, error) {
+	f, err := openDirect(filepath.Join(path, "bench-disk.tmp"))
+	if err != nil {
+		return workloadResult{}, fmt.Errorf("bench: open disk workload file: %w", err)
+	}
+	defer func() {
+		f.Close()
+		os.Remove(f.Name())
+	}()
+
+	buf := randomBuffer()
+	return runChunked(budget, func() error {
+		_, err := f.Write(buf)
+		return err
+	})
+}
+
+// raidWorkload scatters stripeSize stripes round-robin across paths,
+// modeling how a striped RAID spreads sequential I/O across its members.
+func raidWorkload(paths []string, budget time.Duration) (workloadResult, error) {
+	if len(paths) == 0 {
+		return workloadResult{}, fmt.Errorf("bench: raid workload needs at least one path")
+	}
+
+	files := make([]*os.File, len(paths))
+	for i, p := range paths {
+		f, err := openDirect(filepath.Join(p, fmt.Sprintf("bench-raid-%d.tmp", i)))
+		if err != nil {
+			for _, opened := range files[:i] {
+				opened.Close()
+				os.Remove(opened.Name())
+			}
+			return workloadResult{}, fmt.Errorf("bench: open raid workload file %d: %w", i, err)
+		}
+		files[i] = f
+	}
+	defer func() {
+		for _, f := range files {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+
+	buf := randomBuffer()
+	i := 0
+	return runChunked(budget, func() error {
+		_, err := files[i%len(files)].Write(buf)
+		i++
+		return err
+	})
+}
+
+// fuseWorkload repeatedly creates, stats, and removes a tiny file, modeling
+// the small-metadata op pattern a FUSE filesystem's control path sees far
+// more of than bulk data transfer.
+func fuseWorkload(path string, budget time.Duration) (workloadResult, error) {
+	deadline := time.Now().Add(budget)
+	start := time.Now()
+
+	var result workloadResult
+	for time.Now().Before(deadline) {
+		opStart := time.Now()
+
+		name := filepath.Join(path, fmt.Sprintf("bench-fuse-%d.tmp", result.ops))
+		if err := os.WriteFile(name, []byte("x"), 0o600); err != nil {
+			return workloadResult{}, fmt.Errorf("bench: fuse workload write: %w", err)
+		}
+		if _, err := os.Stat(name); err != nil {
+			return workloadResult{}, fmt.Errorf("bench: fuse workload stat: %w", err)
+		}
+		if err := os.Remove(name); err != nil {
+			return workloadResult{}, fmt.Errorf("bench: fuse workload remove: %w", err)
+		}
+
+		result.latencies = append(result.latencies, time.Since(opStart))
+		result.ops++
+	}
+	result.wall = time.Since(start)
+	return result, nil
+}
+
+// runChunked calls op repeatedly until budget elapses, recording the
+// wall-clock latency of each call and assuming stripeSize bytes moved.
+func runChunked(budget time.Duration, op func() error) (workloadResult, error) {
+	deadline := time.Now().Add(budget)
+	start := time.Now()
+
+	var result workloadResult
+	for time.Now().Before(deadline) {
+		opStart := time.Now()
+		if err := op(); err != nil {
+			return workloadResult{}, err
+		}
+		result.latencies = append(result.latencies, time.Since(opStart))
+		result.bytesMoved += stripeSize
+		result.ops++
+	}
+	result.wall = time.Since(start)
+	return result, nil
+}
+
+func randomBuffer() []byte {
+	buf := make([]byte, stripeSize)
+	rand.New(rand.NewSource(1)).Read(buf)
+	return buf
+}
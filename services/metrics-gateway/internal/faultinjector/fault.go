@@ -0,0 +1,196 @@
+// Package faultinjector applies a scripted schedule of faults (disk
+// failures, elevated latency, error bursts) to the simulator on a timeline,
+// so operators can exercise alerting and runbooks against a reproducible
+// incident instead of the simulator's independent per-tick random rolls.
+package faultinjector
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Kind identifies what a Fault does once active.
+type Kind string
+
+const (
+	// KindFail takes target fully offline: zero throughput, all ops error.
+	KindFail Kind = "fail"
+	// KindParityDiskLoss degrades a RAID array by one disk, without
+	// necessarily taking it fully offline.
+	KindParityDiskLoss Kind = "parity-disk-loss"
+	// KindElevatedLatency multiplies target's observed latency by Multiplier.
+	KindElevatedLatency Kind = "elevated-latency"
+	// KindErrorBurst adds Rate errors/second to target's error counter.
+	KindErrorBurst Kind = "error-burst"
+)
+
+// Fault is one entry in a FaultSchedule: Target takes on Kind's behavior
+// starting At and, for the kinds that have one, lasting Duration.
+// Target may be an exact ID ("disk2", "raid3", "fuse") or a trailing-glob
+// ("disk*") matching every ID with that prefix.
+type Fault struct {
+	At         time.Duration
+	Target     string
+	Kind       Kind
+	Multiplier float64
+	Rate       float64
+	Duration   time.Duration
+}
+
+// active reports whether the fault is in effect at now. KindFail and
+// KindParityDiskLoss have no natural end (a disk doesn't un-fail on its
+// own), so a zero Duration means "active from At onward"; the other kinds
+// require an explicit Duration to bound their window.
+func (f Fault) active(now time.Duration) bool {
+	if now < f.At {
+		return false
+	}
+	if f.Duration <= 0 {
+		return true
+	}
+	return now < f.At+f.Duration
+}
+
+// matches reports whether target satisfies the fault's Target pattern.
+func (f Fault) matches(target string) bool {
+	if prefix, ok := strings.CutSuffix(f.Target, "*"); ok {
+		return strings.HasPrefix(target, prefix)
+	}
+	return f.Target == target
+}
+
+// FaultSchedule is an ordered list of faults driving a simulated incident.
+type FaultSchedule struct {
+	Faults []Fault
+}
+
+// ActiveFor returns every fault in fs targeting target that's in effect at
+// now, in schedule order.
+func (fs *FaultSchedule) ActiveFor(target string, now time.Duration) []Fault {
+	if fs == nil {
+		return nil
+	}
+
+	var active []Fault
+	for _, f := range fs.Faults {
+		if f.matches(target) && f.active(now) {
+			active = append(active, f)
+		}
+	}
+	return active
+}
+
+// CascadingDiskFailures counts how many of diskIDs have an active KindFail
+// fault whose At falls within window before now, the trigger condition for
+// rules like "2 disks failing within 60s fails the whole array".
+func (fs *FaultSchedule) CascadingDiskFailures(diskIDs []string, now, window time.Duration) int {
+	if fs == nil {
+		return 0
+	}
+
+	count := 0
+	for _, diskID := range diskIDs {
+		for _, f := range fs.Faults {
+			if f.Kind == KindFail && f.matches(diskID) && f.active(now) && now-f.At <= window {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
+
+// rawFault mirrors Fault's YAML shape, keeping the human-friendly forms
+// ("at: 30s", "multiplier: 10x", "rate: 50/s") as strings so they can be
+// parsed with better error messages than yaml.v3's generic type mismatch.
+type rawFault struct {
+	At         string `yaml:"at"`
+	Target     string `yaml:"target"`
+	Kind       string `yaml:"kind"`
+	Multiplier string `yaml:"multiplier,omitempty"`
+	Rate       string `yaml:"rate,omitempty"`
+	Duration   string `yaml:"duration,omitempty"`
+}
+
+type rawSchedule struct {
+	Faults []rawFault `yaml:"faults"`
+}
+
+// LoadFaultSchedule reads a FaultSchedule from a YAML (or JSON, which
+// parses fine as YAML) file.
+func LoadFaultSchedule(path string) (*FaultSchedule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read fault schedule file: %w", err)
+	}
+
+	var raw rawSchedule
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parse fault schedule file: %w", err)
+	}
+
+	fs := &FaultSchedule{Faults: make([]Fault, 0, len(raw.Faults))}
+	for i, rf := range raw.Faults {
+		f, err := parseFault(rf)
+		if err != nil {
+			return nil, fmt.Errorf("fault schedule entry %d: %w", i, err)
+		}
+		fs.Faults = append(fs.Faults, f)
+	}
+	return fs, nil
+}
+
+func parseFault(rf rawFault) (Fault, error) {
+	at, err := time.ParseDuration(rf.At)
+	if err != nil {
+		return Fault{}, fmt.Errorf("parse at %q: %w", rf.At, err)
+	}
+
+	f := Fault{At: at, Target: rf.Target, Kind: Kind(rf.Kind)}
+
+	if rf.Duration != "" {
+		d, err := time.ParseDuration(rf.Duration)
+		if err != nil {
+			return Fault{}, fmt.Errorf("parse duration %q: %w", rf.Duration, err)
+		}
+		f.Duration = d
+	}
+
+	if rf.Multiplier != "" {
+		m, err := parseMultiplier(rf.Multiplier)
+		if err != nil {
+			return Fault{}, fmt.Errorf("parse multiplier %q: %w", rf.Multiplier, err)
+		}
+		f.Multiplier = m
+	}
+
+	if rf.Rate != "" {
+		r, err := parseRate(rf.Rate)
+		if err != nil {
+			return Fault{}, fmt.Errorf("parse rate %q: %w", rf.Rate, err)
+		}
+		f.Rate = r
+	}
+
+	return f, nil
+}
+
+// parseMultiplier parses the "10x" shorthand into 10.0.
+func parseMultiplier(s string) (float64, error) {
+	return strconv.ParseFloat(strings.TrimSuffix(s, "x"), 64)
+}
+
+// parseRate parses the "50/s" shorthand into 50.0 events per second; only
+// a "/s" (per-second) unit is currently supported.
+func parseRate(s string) (float64, error) {
+	n, unit, ok := strings.Cut(s, "/")
+	if !ok || unit != "s" {
+		return 0, fmt.Errorf("expected a \"<number>/s\" rate, got %q", s)
+	}
+	return strconv.ParseFloat(n, 64)
+}
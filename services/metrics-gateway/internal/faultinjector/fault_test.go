@@ -0,0 +1,122 @@
+package faultinjector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFaultActiveWindow(t *testing.T) {
+	f := Fault{At: 30 * time.Second, Duration: 60 * time.Second}
+
+	cases := []struct {
+		now  time.Duration
+		want bool
+	}{
+		{20 * time.Second, false},
+		{30 * time.Second, true},
+		{60 * time.Second, true},
+		{90 * time.Second, false},
+	}
+	for _, c := range cases {
+		if got := f.active(c.now); got != c.want {
+			t.Errorf("active(%s) = %v, want %v", c.now, got, c.want)
+		}
+	}
+}
+
+func TestFaultWithoutDurationIsPermanent(t *testing.T) {
+	f := Fault{At: 30 * time.Second, Kind: KindFail}
+
+	if f.active(29 * time.Second) {
+		t.Fatal("expected fault inactive before At")
+	}
+	if !f.active(time.Hour) {
+		t.Fatal("expected a zero-Duration fault to stay active indefinitely")
+	}
+}
+
+func TestFaultTargetGlob(t *testing.T) {
+	f := Fault{Target: "disk*"}
+
+	if !f.matches("disk2") {
+		t.Fatal("expected disk* to match disk2")
+	}
+	if f.matches("raid0") {
+		t.Fatal("expected disk* not to match raid0")
+	}
+}
+
+func TestActiveForFiltersByTargetAndWindow(t *testing.T) {
+	fs := &FaultSchedule{Faults: []Fault{
+		{At: 10 * time.Second, Target: "disk2", Kind: KindFail},
+		{At: 20 * time.Second, Target: "fuse", Kind: KindElevatedLatency, Multiplier: 10, Duration: 60 * time.Second},
+	}}
+
+	active := fs.ActiveFor("disk2", 15*time.Second)
+	if len(active) != 1 || active[0].Kind != KindFail {
+		t.Fatalf("expected one KindFail active for disk2, got %+v", active)
+	}
+
+	if active := fs.ActiveFor("fuse", 10*time.Second); len(active) != 0 {
+		t.Fatalf("expected no fuse faults active before At, got %+v", active)
+	}
+}
+
+func TestCascadingDiskFailuresCounts(t *testing.T) {
+	fs := &FaultSchedule{Faults: []Fault{
+		{At: 10 * time.Second, Target: "disk0", Kind: KindFail},
+		{At: 40 * time.Second, Target: "disk1", Kind: KindFail},
+	}}
+
+	diskIDs := []string{"disk0", "disk1", "disk2"}
+
+	if got := fs.CascadingDiskFailures(diskIDs, 50*time.Second, 60*time.Second); got != 2 {
+		t.Fatalf("expected both failures within the 60s window, got %d", got)
+	}
+	if got := fs.CascadingDiskFailures(diskIDs, 50*time.Second, 5*time.Second); got != 1 {
+		t.Fatalf("expected only the more recent failure within a 5s window, got %d", got)
+	}
+}
+
+func TestLoadFaultScheduleParsesHumanFriendlyFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schedule.yaml")
+	yaml := `
+faults:
+  - at: 30s
+    target: disk2
+    kind: fail
+  - at: 2m
+    target: fuse
+    kind: elevated-latency
+    multiplier: 10x
+    duration: 60s
+  - at: 3m
+    target: "disk*"
+    kind: error-burst
+    rate: 50/s
+    duration: 10s
+`
+	if err := os.WriteFile(path, []byte(yaml), 0o644); err != nil {
+		t.Fatalf("write schedule file: %v", err)
+	}
+
+	fs, err := LoadFaultSchedule(path)
+	if err != nil {
+		t.Fatalf("LoadFaultSchedule: %v", err)
+	}
+	if len(fs.Faults) != 3 {
+		t.Fatalf("expected 3 faults, got %d", len(fs.Faults))
+	}
+
+	if fs.Faults[0].At != 30*time.Second || fs.Faults[0].Kind != KindFail {
+		t.Errorf("fault 0 = %+v, want at=30s kind=fail", fs.Faults[0])
+	}
+	if fs.Faults[1].Multiplier != 10 || fs.Faults[1].Duration != 60*time.Second {
+		t.Errorf("fault 1 = %+v, want multiplier=10 duration=60s", fs.Faults[1])
+	}
+	if fs.Faults[2].Rate != 50 || fs.Faults[2].Target != "disk*" {
+		t.Errorf("fault 2 = %+v, want rate=50 target=disk*", fs.Faults[2])
+	}
+}
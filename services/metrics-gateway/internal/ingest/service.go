@@ -1,37 +1,74 @@
 package ingest
 
 import (
+	"context"
 	"io"
 	"math"
 	"regexp"
 
+	"metrics-gateway/internal/ingest/wal"
 	"metrics-gateway/internal/metrics"
 	pb "metrics-gateway/internal/pb/metrics/v1"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
+var tracer = otel.Tracer("metrics-gateway/internal/ingest")
+
 var idRe = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
 
 type Service struct {
 	pb.UnimplementedMetricsIngestorServer
-	m *metrics.AllMetrics
+	m   *metrics.AllMetrics
+	wal *wal.WAL
 }
 
 func NewService(m *metrics.AllMetrics) *Service {
-	return &Service{m: m}
+	return NewServiceWithWAL(m, nil)
+}
+
+// NewServiceWithWAL is like NewService but durably logs every accepted batch
+// to w before applying it, so Apply can be replayed against a fresh Service
+// on restart. w may be nil, in which case no WAL is used.
+func NewServiceWithWAL(m *metrics.AllMetrics, w *wal.WAL) *Service {
+	return &Service{m: m, wal: w}
+}
+
+// Apply runs a single batch through the same handlers Push uses, without the
+// stream/span plumbing. It's the replay entry point main.go calls for every
+// batch recovered from the WAL on startup.
+func (s *Service) Apply(batch *pb.MetricsBatch) error {
+	var c pushCounters
+	s.handleDiskOps(batch.GetDiskOps(), &c)
+	s.handleDiskStates(batch.GetDiskStates(), &c)
+	s.handleRaidOps(batch.GetRaidOps(), &c)
+	s.handleRaidStates(batch.GetRaidStates(), &c)
+	s.handleFuseOps(batch.GetFuseOps(), &c)
+	s.handleProcess(batch.GetProcess(), &c)
+	return nil
 }
 
 type pushCounters struct {
 	acceptedBatches uint64
 	acceptedSamples uint64
 	rejectedSamples uint64
+
+	span trace.Span
 }
 
 func (c *pushCounters) acceptSample() { c.acceptedSamples++ }
-func (c *pushCounters) rejectSample() { c.rejectedSamples++ }
+
+func (c *pushCounters) rejectSample(reason string) {
+	c.rejectedSamples++
+	if c.span != nil {
+		c.span.AddEvent("sample_rejected", trace.WithAttributes(attribute.String("reason", reason)))
+	}
+}
 
 func (s *Service) Push(stream pb.MetricsIngestor_PushServer) error {
 	var c pushCounters
@@ -50,29 +87,55 @@ func (s *Service) Push(stream pb.MetricsIngestor_PushServer) error {
 		}
 
 		if batch.GetSourceId() == "" {
-			c.rejectSample()
+			c.rejectSample("missing source_id")
 			continue
 		}
 
-		c.acceptedBatches++
+		if s.wal != nil {
+			if err := s.wal.Append(batch); err != nil {
+				return status.Errorf(codes.Unavailable, "wal append: %v", err)
+			}
+		}
 
-		s.handleDiskOps(batch.GetDiskOps(), &c)
-		s.handleDiskStates(batch.GetDiskStates(), &c)
-		s.handleRaidOps(batch.GetRaidOps(), &c)
-		s.handleRaidStates(batch.GetRaidStates(), &c)
-		s.handleFuseOps(batch.GetFuseOps(), &c)
-		s.handleProcess(batch.GetProcess(), &c)
+		c.acceptedBatches++
+		s.pushBatch(stream.Context(), batch, &c)
 	}
 }
 
+// pushBatch applies a single validated batch, wrapping it in a child span
+// that records the source, and the batch's accepted/rejected delta so a
+// slow producer can be traced down to the rejected sample that explains it.
+func (s *Service) pushBatch(ctx context.Context, batch *pb.MetricsBatch, c *pushCounters) {
+	_, span := tracer.Start(ctx, "ingest.push_batch")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("source_id", batch.GetSourceId()))
+
+	acceptedBefore, rejectedBefore := c.acceptedSamples, c.rejectedSamples
+	c.span = span
+
+	s.handleDiskOps(batch.GetDiskOps(), c)
+	s.handleDiskStates(batch.GetDiskStates(), c)
+	s.handleRaidOps(batch.GetRaidOps(), c)
+	s.handleRaidStates(batch.GetRaidStates(), c)
+	s.handleFuseOps(batch.GetFuseOps(), c)
+	s.handleProcess(batch.GetProcess(), c)
+
+	c.span = nil
+	span.SetAttributes(
+		attribute.Int64("accepted_samples", int64(c.acceptedSamples-acceptedBefore)),
+		attribute.Int64("rejected_samples", int64(c.rejectedSamples-rejectedBefore)),
+	)
+}
+
 func (s *Service) handleDiskOps(ops []*pb.DiskOp, c *pushCounters) {
 	for _, op := range ops {
 		if !validateDiskOp(op) {
-			c.rejectSample()
+			c.rejectSample("invalid disk op: bad disk_id or latency")
 			continue
 		}
 		if !s.applyDiskOp(op) {
-			c.rejectSample()
+			c.rejectSample("invalid disk op: unsupported op type")
 			continue
 		}
 		c.acceptSample()
@@ -117,7 +180,7 @@ func (s *Service) applyDiskOp(op *pb.DiskOp) bool {
 func (s *Service) handleDiskStates(states []*pb.DiskState, c *pushCounters) {
 	for _, st := range states {
 		if !validateDiskState(st) {
-			c.rejectSample()
+			c.rejectSample("invalid disk state: bad disk_id or queue_depth")
 			continue
 		}
 		s.applyDiskState(st)
@@ -136,11 +199,11 @@ func (s *Service) applyDiskState(st *pb.DiskState) {
 func (s *Service) handleRaidOps(ops []*pb.RaidOp, c *pushCounters) {
 	for _, op := range ops {
 		if !validateRaidOp(op) {
-			c.rejectSample()
+			c.rejectSample("invalid raid op: bad raid_id or latency")
 			continue
 		}
 		if !s.applyRaidOp(op) {
-			c.rejectSample()
+			c.rejectSample("invalid raid op: unsupported op type")
 			continue
 		}
 		c.acceptSample()
@@ -207,7 +270,7 @@ func (s *Service) applyRaidWrite(op *pb.RaidOp) {
 func (s *Service) handleRaidStates(states []*pb.RaidState, c *pushCounters) {
 	for _, st := range states {
 		if !validateRaidState(st) {
-			c.rejectSample()
+			c.rejectSample("invalid raid state: bad raid_id or resync progress")
 			continue
 		}
 		s.applyRaidState(st)
@@ -231,11 +294,11 @@ func (s *Service) applyRaidState(st *pb.RaidState) {
 func (s *Service) handleFuseOps(ops []*pb.FuseOp, c *pushCounters) {
 	for _, op := range ops {
 		if !validateFuseOp(op) {
-			c.rejectSample()
+			c.rejectSample("invalid fuse op: bad latency")
 			continue
 		}
 		if !s.applyFuseOp(op) {
-			c.rejectSample()
+			c.rejectSample("invalid fuse op: unsupported op type")
 			continue
 		}
 		c.acceptSample()
@@ -288,7 +351,7 @@ func (s *Service) handleProcess(ps *pb.ProcessSample, c *pushCounters) {
 		s.m.Process.CPUSeconds.Set(ps.GetCpuSeconds())
 		c.acceptSample()
 	} else {
-		c.rejectSample()
+		c.rejectSample("invalid process sample: non-finite cpu_seconds")
 	}
 
 	s.m.Process.ResidentMemory.Set(float64(ps.GetResidentMemoryBytes()))
@@ -9,7 +9,9 @@ import (
 	"metrics-gateway/internal/metrics"
 	pb "metrics-gateway/internal/pb/metrics/v1"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
 )
 
 func newTestService(t *testing.T) *Service {
@@ -23,6 +25,24 @@ func newTestService(t *testing.T) *Service {
 	return NewService(all)
 }
 
+// histogramSampleCountAndSum reads a histogram's _count/_sum children
+// directly off its protobuf representation, since testutil.ToFloat64 only
+// supports single-value metrics (Gauge/Counter/Untyped).
+func histogramSampleCountAndSum(t *testing.T, o prometheus.Observer) (count uint64, sum float64) {
+	t.Helper()
+
+	h, ok := o.(prometheus.Metric)
+	if !ok {
+		t.Fatalf("observer %T does not implement prometheus.Metric", o)
+	}
+
+	var m dto.Metric
+	if err := h.Write(&m); err != nil {
+		t.Fatalf("write histogram metric: %v", err)
+	}
+	return m.GetHistogram().GetSampleCount(), m.GetHistogram().GetSampleSum()
+}
+
 func TestApplyDiskOpReadUpdatesMetrics(t *testing.T) {
 	svc := newTestService(t)
 
@@ -47,6 +67,14 @@ func TestApplyDiskOpReadUpdatesMetrics(t *testing.T) {
 	if v := testutil.ToFloat64(svc.m.Disks.Errors.WithLabelValues("disk0")); v != 1 {
 		t.Fatalf("expected errors to be 1, got %f", v)
 	}
+
+	count, sum := histogramSampleCountAndSum(t, svc.m.Disks.ReadLatency.WithLabelValues("disk0"))
+	if count != 1 {
+		t.Fatalf("expected read latency histogram to have 1 observation, got %d", count)
+	}
+	if sum != 0.5 {
+		t.Fatalf("expected read latency histogram sum to be 0.5, got %f", sum)
+	}
 }
 
 func TestApplyRaidWriteUpdatesMetrics(t *testing.T) {
@@ -288,6 +316,13 @@ func TestHandleFuseOpsTracksAllOps(t *testing.T) {
 	if v := testutil.ToFloat64(svc.m.Fuse.Errors); v != 1 {
 		t.Fatalf("expected fuse errors to be 1, got %f", v)
 	}
+
+	if count, sum := histogramSampleCountAndSum(t, svc.m.Fuse.ReadLatency); count != 1 || sum != 0.1 {
+		t.Fatalf("expected read latency histogram count=1 sum=0.1, got count=%d sum=%f", count, sum)
+	}
+	if count, sum := histogramSampleCountAndSum(t, svc.m.Fuse.WriteLatency); count != 1 || sum != 0.2 {
+		t.Fatalf("expected write latency histogram count=1 sum=0.2, got count=%d sum=%f", count, sum)
+	}
 }
 
 func TestApplyRaidReadTracksExtras(t *testing.T) {
@@ -330,6 +365,12 @@ func TestRecordIOAndHelpers(t *testing.T) {
 		t.Fatalf("expected read bytes to be 25, got %f", v)
 	}
 
+	// The first Observe call used a negative latency, which observeIfPositive
+	// skips, so only the second (0.5) should have landed in the histogram.
+	if count, sum := histogramSampleCountAndSum(t, svc.m.Fuse.ReadLatency); count != 1 || sum != 0.5 {
+		t.Fatalf("expected read latency histogram count=1 sum=0.5, got count=%d sum=%f", count, sum)
+	}
+
 	if !validID("disk_1") {
 		t.Fatal("expected id to be valid")
 	}
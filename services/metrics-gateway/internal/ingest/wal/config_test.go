@@ -0,0 +1,52 @@
+package wal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadConfigFromEnvDisabledWithoutDir(t *testing.T) {
+	cfg := LoadConfigFromEnv()
+	if cfg.Enabled() {
+		t.Fatalf("expected WAL to be disabled when INGEST_WAL_DIR is unset")
+	}
+}
+
+func TestLoadConfigFromEnvParsesFields(t *testing.T) {
+	t.Setenv("INGEST_WAL_DIR", "/tmp/wal")
+	t.Setenv("INGEST_WAL_SEGMENT_BYTES", "2048")
+	t.Setenv("INGEST_WAL_MAX_TOTAL_BYTES", "4096")
+	t.Setenv("INGEST_WAL_FSYNC", "always")
+
+	cfg := LoadConfigFromEnv()
+
+	if !cfg.Enabled() {
+		t.Fatalf("expected WAL to be enabled once INGEST_WAL_DIR is set")
+	}
+	if cfg.SegmentBytes != 2048 {
+		t.Errorf("expected SegmentBytes 2048, got %d", cfg.SegmentBytes)
+	}
+	if cfg.MaxTotalBytes != 4096 {
+		t.Errorf("expected MaxTotalBytes 4096, got %d", cfg.MaxTotalBytes)
+	}
+	if cfg.Fsync != FsyncAlways {
+		t.Errorf("expected FsyncAlways, got %v", cfg.Fsync)
+	}
+}
+
+func TestParseFsyncInterval(t *testing.T) {
+	policy, interval := parseFsync("interval:250ms")
+	if policy != FsyncInterval {
+		t.Fatalf("expected FsyncInterval, got %v", policy)
+	}
+	if interval != 250*time.Millisecond {
+		t.Fatalf("expected 250ms interval, got %s", interval)
+	}
+}
+
+func TestParseFsyncOff(t *testing.T) {
+	policy, _ := parseFsync("off")
+	if policy != FsyncOff {
+		t.Fatalf("expected FsyncOff, got %v", policy)
+	}
+}
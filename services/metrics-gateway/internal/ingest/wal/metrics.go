@@ -0,0 +1,27 @@
+package wal
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Metrics are the WAL-depth/fsync-latency gauges a WAL reports into, owned
+// by the caller's registry (see metrics.NewWALMetrics) so the wal package
+// doesn't need its own registration lifecycle.
+type Metrics struct {
+	DepthBytes          prometheus.Gauge
+	SegmentCount        prometheus.Gauge
+	FsyncLatencySeconds prometheus.Gauge
+}
+
+func (m Metrics) setDepth(bytes, segments int64) {
+	if m.DepthBytes != nil {
+		m.DepthBytes.Set(float64(bytes))
+	}
+	if m.SegmentCount != nil {
+		m.SegmentCount.Set(float64(segments))
+	}
+}
+
+func (m Metrics) observeFsync(seconds float64) {
+	if m.FsyncLatencySeconds != nil {
+		m.FsyncLatencySeconds.Set(seconds)
+	}
+}
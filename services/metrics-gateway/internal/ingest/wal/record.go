@@ -0,0 +1,40 @@
+package wal
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// writeRecord appends a length-prefixed protobuf record to w.
+func writeRecord(w io.Writer, payload []byte) (int, error) {
+	var lenBuf [4]byte
+	binary.BigEndian.PutUint32(lenBuf[:], uint32(len(payload)))
+
+	n, err := w.Write(lenBuf[:])
+	if err != nil {
+		return n, err
+	}
+	m, err := w.Write(payload)
+	return n + m, err
+}
+
+// readRecord reads one length-prefixed record from r, returning io.EOF when
+// the stream ends cleanly on a record boundary.
+func readRecord(r io.Reader) ([]byte, error) {
+	var lenBuf [4]byte
+	if _, err := io.ReadFull(r, lenBuf[:]); err != nil {
+		return nil, err
+	}
+
+	n := binary.BigEndian.Uint32(lenBuf[:])
+	if n > 64<<20 {
+		return nil, fmt.Errorf("wal: implausible record length %d", n)
+	}
+
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, fmt.Errorf("wal: truncated record: %w", err)
+	}
+	return buf, nil
+}
@@ -0,0 +1,140 @@
+package wal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	pb "metrics-gateway/internal/pb/metrics/v1"
+
+	"google.golang.org/protobuf/proto"
+)
+
+const checkpointFile = "checkpoint.json"
+
+// Checkpoint records the last segment/offset pair whose effects are known to
+// have been exported via /metrics, so Replay has a bound rather than
+// replaying the whole WAL unconditionally on every restart.
+type Checkpoint struct {
+	Segment int   `json:"segment"`
+	Offset  int64 `json:"offset"`
+}
+
+// LoadCheckpoint reads the checkpoint file from dir. A missing file returns
+// the zero Checkpoint (replay everything).
+func LoadCheckpoint(dir string) (Checkpoint, error) {
+	data, err := os.ReadFile(filepath.Join(dir, checkpointFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return Checkpoint{}, nil
+	}
+	if err != nil {
+		return Checkpoint{}, fmt.Errorf("wal: read checkpoint: %w", err)
+	}
+
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return Checkpoint{}, fmt.Errorf("wal: parse checkpoint: %w", err)
+	}
+	return cp, nil
+}
+
+// SaveCheckpoint atomically writes cp to dir. It's called from the /metrics
+// scrape handler's post-gather hook, advancing the replay bound only after
+// the WAL's effects have actually left the process.
+func SaveCheckpoint(dir string, cp Checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("wal: marshal checkpoint: %w", err)
+	}
+
+	tmp := filepath.Join(dir, checkpointFile+".tmp")
+	if err := os.WriteFile(tmp, data, 0o640); err != nil {
+		return fmt.Errorf("wal: write checkpoint tmp: %w", err)
+	}
+	return os.Rename(tmp, filepath.Join(dir, checkpointFile))
+}
+
+// Replay feeds every batch recorded after cp through apply, in segment
+// order, then removes segments that were fully replayed (i.e. every segment
+// strictly before the last one touched). It returns the Checkpoint that
+// should be persisted once apply's effects are safely exported.
+func Replay(dir string, cp Checkpoint, apply func(*pb.MetricsBatch) error) (Checkpoint, error) {
+	segs, err := listSegments(dir)
+	if err != nil {
+		return cp, err
+	}
+
+	last := cp
+	for _, seq := range segs {
+		if seq < cp.Segment {
+			continue
+		}
+
+		offset, err := replaySegment(dir, seq, startOffsetFor(seq, cp), apply)
+		if err != nil {
+			return last, err
+		}
+		last = Checkpoint{Segment: seq, Offset: offset}
+	}
+
+	truncateReplayed(dir, segs, last.Segment)
+	return last, nil
+}
+
+func startOffsetFor(seq int, cp Checkpoint) int64 {
+	if seq == cp.Segment {
+		return cp.Offset
+	}
+	return 0
+}
+
+func replaySegment(dir string, seq int, startOffset int64, apply func(*pb.MetricsBatch) error) (int64, error) {
+	path := filepath.Join(dir, segmentName(seq))
+	f, err := os.Open(path)
+	if err != nil {
+		return startOffset, fmt.Errorf("wal: open segment %s: %w", path, err)
+	}
+	defer f.Close()
+
+	if startOffset > 0 {
+		if _, err := f.Seek(startOffset, io.SeekStart); err != nil {
+			return startOffset, fmt.Errorf("wal: seek segment %s: %w", path, err)
+		}
+	}
+
+	offset := startOffset
+	for {
+		pos := offset
+		payload, err := readRecord(f)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			// A truncated trailing record means the process crashed mid-write;
+			// stop replaying this segment at the last complete record.
+			break
+		}
+		offset = pos + 4 + int64(len(payload))
+
+		var batch pb.MetricsBatch
+		if err := proto.Unmarshal(payload, &batch); err != nil {
+			break
+		}
+		if err := apply(&batch); err != nil {
+			return pos, err
+		}
+	}
+
+	return offset, nil
+}
+
+func truncateReplayed(dir string, segs []int, throughSeq int) {
+	for _, seq := range segs {
+		if seq < throughSeq {
+			_ = os.Remove(filepath.Join(dir, segmentName(seq)))
+		}
+	}
+}
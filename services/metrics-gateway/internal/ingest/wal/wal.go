@@ -0,0 +1,241 @@
+package wal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	pb "metrics-gateway/internal/pb/metrics/v1"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// WAL is a segmented append-only log of ingested MetricsBatch protobufs.
+// Append is safe for concurrent use.
+type WAL struct {
+	mu  sync.Mutex
+	cfg Config
+	m   Metrics
+
+	curFile   *os.File
+	curSeq    int
+	curSize   int64
+	curOpened time.Time
+
+	lastFsync time.Time
+}
+
+// Open opens (creating if necessary) the WAL directory and starts a fresh
+// segment for new appends. Existing segments are left untouched for Replay.
+func Open(cfg Config, m Metrics) (*WAL, error) {
+	if err := os.MkdirAll(cfg.Dir, 0o770); err != nil {
+		return nil, fmt.Errorf("wal: mkdir %s: %w", cfg.Dir, err)
+	}
+
+	segs, err := listSegments(cfg.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	nextSeq := 1
+	if len(segs) > 0 {
+		nextSeq = segs[len(segs)-1] + 1
+	}
+
+	w := &WAL{cfg: cfg, m: m}
+	if err := w.rollLocked(nextSeq); err != nil {
+		return nil, err
+	}
+
+	w.reportDepthLocked()
+	return w, nil
+}
+
+func listSegments(dir string) ([]int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("wal: list segments in %s: %w", dir, err)
+	}
+
+	var seqs []int
+	for _, e := range entries {
+		var seq int
+		if _, err := fmt.Sscanf(e.Name(), "seg-%08d.wal", &seq); err == nil {
+			seqs = append(seqs, seq)
+		}
+	}
+	sort.Ints(seqs)
+	return seqs, nil
+}
+
+func (w *WAL) rollLocked(seq int) error {
+	if w.curFile != nil {
+		_ = w.curFile.Close()
+	}
+
+	path := filepath.Join(w.cfg.Dir, segmentName(seq))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o660)
+	if err != nil {
+		return fmt.Errorf("wal: open segment %s: %w", path, err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("wal: stat segment %s: %w", path, err)
+	}
+
+	w.curFile = f
+	w.curSeq = seq
+	w.curSize = info.Size()
+	w.curOpened = time.Now()
+	return nil
+}
+
+func (w *WAL) shouldRollLocked() bool {
+	if w.cfg.SegmentBytes > 0 && w.curSize >= w.cfg.SegmentBytes {
+		return true
+	}
+	if w.cfg.SegmentMaxAge > 0 && time.Since(w.curOpened) >= w.cfg.SegmentMaxAge {
+		return true
+	}
+	return false
+}
+
+// Append serializes batch and writes it to the current segment, rotating to
+// a new segment first if the current one has reached SegmentBytes.
+func (w *WAL) Append(batch *pb.MetricsBatch) error {
+	payload, err := proto.Marshal(batch)
+	if err != nil {
+		return fmt.Errorf("wal: marshal batch: %w", err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.shouldRollLocked() {
+		if err := w.rollLocked(w.curSeq + 1); err != nil {
+			return err
+		}
+	}
+
+	n, err := writeRecord(w.curFile, payload)
+	if err != nil {
+		return fmt.Errorf("wal: append record: %w", err)
+	}
+	w.curSize += int64(n)
+
+	if err := w.maybeFsyncLocked(); err != nil {
+		return err
+	}
+
+	w.reportDepthLocked()
+	return nil
+}
+
+func (w *WAL) maybeFsyncLocked() error {
+	switch w.cfg.Fsync {
+	case FsyncOff:
+		return nil
+	case FsyncInterval:
+		if time.Since(w.lastFsync) < w.cfg.FsyncInterval {
+			return nil
+		}
+	}
+
+	start := time.Now()
+	err := w.curFile.Sync()
+	w.m.observeFsync(time.Since(start).Seconds())
+	w.lastFsync = time.Now()
+
+	if err != nil {
+		return fmt.Errorf("wal: fsync: %w", err)
+	}
+	return nil
+}
+
+// PruneThrough removes on-disk segments strictly before cp.Segment once the
+// WAL directory's total size exceeds cfg.MaxTotalBytes (a no-op if
+// MaxTotalBytes is unset). It only ever removes segments cp already covers,
+// the same set Replay would truncate on the next restart, so a long-running
+// process enforces the same bound at runtime instead of only at startup,
+// without risking a segment a crash would still need to recover.
+func (w *WAL) PruneThrough(cp Checkpoint) error {
+	if w.cfg.MaxTotalBytes <= 0 {
+		return nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	segs, err := listSegments(w.cfg.Dir)
+	if err != nil {
+		return err
+	}
+
+	sizes := make(map[int]int64, len(segs))
+	var total int64
+	for _, seq := range segs {
+		info, err := os.Stat(filepath.Join(w.cfg.Dir, segmentName(seq)))
+		if err != nil {
+			continue
+		}
+		sizes[seq] = info.Size()
+		total += info.Size()
+	}
+
+	for _, seq := range segs {
+		if total <= w.cfg.MaxTotalBytes || seq >= cp.Segment {
+			break
+		}
+		if err := os.Remove(filepath.Join(w.cfg.Dir, segmentName(seq))); err != nil {
+			continue
+		}
+		total -= sizes[seq]
+	}
+
+	w.reportDepthLocked()
+	return nil
+}
+
+func (w *WAL) reportDepthLocked() {
+	segs, _ := listSegments(w.cfg.Dir)
+
+	var total int64
+	for _, seq := range segs {
+		if info, err := os.Stat(filepath.Join(w.cfg.Dir, segmentName(seq))); err == nil {
+			total += info.Size()
+		}
+	}
+	w.m.setDepth(total, int64(len(segs)))
+}
+
+// Dir returns the configured WAL directory.
+func (w *WAL) Dir() string { return w.cfg.Dir }
+
+// Position returns the current write position: every record appended before
+// this call returns has already had its effects applied, so it's a safe
+// Checkpoint to persist once those effects have also been exported.
+func (w *WAL) Position() Checkpoint {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return Checkpoint{Segment: w.curSeq, Offset: w.curSize}
+}
+
+// Close flushes and closes the active segment.
+func (w *WAL) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.curFile == nil {
+		return nil
+	}
+	if err := w.curFile.Sync(); err != nil {
+		_ = w.curFile.Close()
+		return fmt.Errorf("wal: final sync: %w", err)
+	}
+	return w.curFile.Close()
+}
@@ -0,0 +1,274 @@
+package wal
+
+import (
+	"testing"
+	"time"
+
+	pb "metrics-gateway/internal/pb/metrics/v1"
+)
+
+func newTestWAL(t *testing.T, cfg Config) *WAL {
+	t.Helper()
+
+	w, err := Open(cfg, Metrics{})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = w.Close()
+	})
+	return w
+}
+
+func TestAppendAndReplayRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	cfg := Config{Dir: dir, SegmentBytes: 1 << 20, Fsync: FsyncOff}
+
+	w := newTestWAL(t, cfg)
+
+	want := []*pb.MetricsBatch{
+		{SourceId: "src-a"},
+		{SourceId: "src-b"},
+		{SourceId: "src-c"},
+	}
+	for _, b := range want {
+		if err := w.Append(b); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+
+	var got []*pb.MetricsBatch
+	cp, err := Replay(dir, Checkpoint{}, func(b *pb.MetricsBatch) error {
+		got = append(got, b)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("expected %d replayed batches, got %d", len(want), len(got))
+	}
+	for i, b := range got {
+		if b.GetSourceId() != want[i].GetSourceId() {
+			t.Fatalf("batch %d: expected source_id %q, got %q", i, want[i].GetSourceId(), b.GetSourceId())
+		}
+	}
+
+	if cp.Segment != w.curSeq {
+		t.Fatalf("expected checkpoint segment %d, got %d", w.curSeq, cp.Segment)
+	}
+}
+
+func TestReplayIsBoundedByCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	cfg := Config{Dir: dir, SegmentBytes: 1 << 20, Fsync: FsyncOff}
+
+	w := newTestWAL(t, cfg)
+
+	for _, id := range []string{"src-a", "src-b"} {
+		if err := w.Append(&pb.MetricsBatch{SourceId: id}); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+
+	cp := w.Position()
+
+	if err := w.Append(&pb.MetricsBatch{SourceId: "src-c"}); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+
+	var got []string
+	if _, err := Replay(dir, cp, func(b *pb.MetricsBatch) error {
+		got = append(got, b.GetSourceId())
+		return nil
+	}); err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+
+	if len(got) != 1 || got[0] != "src-c" {
+		t.Fatalf("expected replay to resume after checkpoint with only [src-c], got %v", got)
+	}
+}
+
+func TestAppendRollsOverAtSegmentBytes(t *testing.T) {
+	dir := t.TempDir()
+	cfg := Config{Dir: dir, SegmentBytes: 1, Fsync: FsyncOff}
+
+	w := newTestWAL(t, cfg)
+
+	for i := 0; i < 3; i++ {
+		if err := w.Append(&pb.MetricsBatch{SourceId: "src"}); err != nil {
+			t.Fatalf("Append %d: %v", i, err)
+		}
+	}
+
+	segs, err := listSegments(dir)
+	if err != nil {
+		t.Fatalf("listSegments: %v", err)
+	}
+	if len(segs) < 3 {
+		t.Fatalf("expected at least 3 segments after tiny SegmentBytes, got %d", len(segs))
+	}
+}
+
+func TestReplayTruncatesFullyConsumedSegments(t *testing.T) {
+	dir := t.TempDir()
+	cfg := Config{Dir: dir, SegmentBytes: 1, Fsync: FsyncOff}
+
+	w := newTestWAL(t, cfg)
+	for i := 0; i < 3; i++ {
+		if err := w.Append(&pb.MetricsBatch{SourceId: "src"}); err != nil {
+			t.Fatalf("Append %d: %v", i, err)
+		}
+	}
+
+	before, err := listSegments(dir)
+	if err != nil {
+		t.Fatalf("listSegments: %v", err)
+	}
+
+	if _, err := Replay(dir, Checkpoint{}, func(*pb.MetricsBatch) error { return nil }); err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+
+	after, err := listSegments(dir)
+	if err != nil {
+		t.Fatalf("listSegments: %v", err)
+	}
+
+	if len(after) >= len(before) {
+		t.Fatalf("expected Replay to truncate earlier segments, had %d before and %d after", len(before), len(after))
+	}
+}
+
+func TestPruneThroughRemovesSegmentsOverMaxTotalBytes(t *testing.T) {
+	dir := t.TempDir()
+	cfg := Config{Dir: dir, SegmentBytes: 1, MaxTotalBytes: 1, Fsync: FsyncOff}
+
+	w := newTestWAL(t, cfg)
+	for i := 0; i < 3; i++ {
+		if err := w.Append(&pb.MetricsBatch{SourceId: "src"}); err != nil {
+			t.Fatalf("Append %d: %v", i, err)
+		}
+	}
+
+	before, err := listSegments(dir)
+	if err != nil {
+		t.Fatalf("listSegments: %v", err)
+	}
+
+	if err := w.PruneThrough(Checkpoint{Segment: w.curSeq}); err != nil {
+		t.Fatalf("PruneThrough: %v", err)
+	}
+
+	after, err := listSegments(dir)
+	if err != nil {
+		t.Fatalf("listSegments: %v", err)
+	}
+	if len(after) >= len(before) {
+		t.Fatalf("expected PruneThrough to remove segments over MaxTotalBytes, had %d before and %d after", len(before), len(after))
+	}
+	if after[len(after)-1] != w.curSeq {
+		t.Fatalf("expected the active segment %d to survive pruning, got segments %v", w.curSeq, after)
+	}
+}
+
+func TestPruneThroughIsNoOpWithoutMaxTotalBytes(t *testing.T) {
+	dir := t.TempDir()
+	cfg := Config{Dir: dir, SegmentBytes: 1, Fsync: FsyncOff}
+
+	w := newTestWAL(t, cfg)
+	for i := 0; i < 3; i++ {
+		if err := w.Append(&pb.MetricsBatch{SourceId: "src"}); err != nil {
+			t.Fatalf("Append %d: %v", i, err)
+		}
+	}
+
+	before, err := listSegments(dir)
+	if err != nil {
+		t.Fatalf("listSegments: %v", err)
+	}
+
+	if err := w.PruneThrough(Checkpoint{Segment: w.curSeq}); err != nil {
+		t.Fatalf("PruneThrough: %v", err)
+	}
+
+	after, err := listSegments(dir)
+	if err != nil {
+		t.Fatalf("listSegments: %v", err)
+	}
+	if len(after) != len(before) {
+		t.Fatalf("expected PruneThrough to be a no-op when MaxTotalBytes is unset, had %d before and %d after", len(before), len(after))
+	}
+}
+
+func TestCheckpointSaveAndLoadRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := LoadCheckpoint(dir); err != nil {
+		t.Fatalf("LoadCheckpoint on missing file: %v", err)
+	}
+
+	want := Checkpoint{Segment: 3, Offset: 128}
+	if err := SaveCheckpoint(dir, want); err != nil {
+		t.Fatalf("SaveCheckpoint: %v", err)
+	}
+
+	got, err := LoadCheckpoint(dir)
+	if err != nil {
+		t.Fatalf("LoadCheckpoint: %v", err)
+	}
+	if got != want {
+		t.Fatalf("expected checkpoint %+v, got %+v", want, got)
+	}
+}
+
+func TestAppendFsyncOffDoesNotBlockOnSync(t *testing.T) {
+	dir := t.TempDir()
+	cfg := Config{Dir: dir, SegmentBytes: 1 << 20, Fsync: FsyncOff}
+
+	w := newTestWAL(t, cfg)
+
+	start := time.Now()
+	for i := 0; i < 50; i++ {
+		if err := w.Append(&pb.MetricsBatch{SourceId: "src"}); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected FsyncOff appends to be fast, took %s", elapsed)
+	}
+}
+
+func TestOpenResumesAfterSegmentsOnDisk(t *testing.T) {
+	dir := t.TempDir()
+	cfg := Config{Dir: dir, SegmentBytes: 1 << 20, Fsync: FsyncOff}
+
+	w1, err := Open(cfg, Metrics{})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if err := w1.Append(&pb.MetricsBatch{SourceId: "src"}); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	if err := w1.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	w2 := newTestWAL(t, cfg)
+	if w2.curSeq <= w1.curSeq {
+		t.Fatalf("expected new WAL to continue after segment %d, got %d", w1.curSeq, w2.curSeq)
+	}
+
+	segs, err := listSegments(dir)
+	if err != nil {
+		t.Fatalf("listSegments: %v", err)
+	}
+	if len(segs) != 2 {
+		t.Fatalf("expected the old segment to remain on disk for replay, found %d segments", len(segs))
+	}
+	if segs[0] != w1.curSeq {
+		t.Fatalf("expected original segment %d to still exist, got %v", w1.curSeq, segs)
+	}
+}
@@ -0,0 +1,115 @@
+package graphite
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Bridge periodically gathers a registry and pushes it to a Graphite carbon
+// endpoint. It is safe for use only from the goroutine running Run; Run is
+// the sole caller of every unexported method below.
+type Bridge struct {
+	cfg Config
+	reg prometheus.Gatherer
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewBridge returns a Bridge that will push everything reg.Gather() returns
+// to cfg.URL once per cfg.Interval once Run is started.
+func NewBridge(cfg Config, reg prometheus.Gatherer) *Bridge {
+	return &Bridge{cfg: cfg, reg: reg}
+}
+
+// Run pushes the registry on cfg.Interval until ctx is canceled, at which
+// point it closes its connection and returns. Callers that want Run to run
+// in the background (the common case) should invoke it via `go`.
+func (b *Bridge) Run(ctx context.Context) {
+	ticker := time.NewTicker(b.cfg.Interval)
+	defer ticker.Stop()
+	defer b.closeConn()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := b.pushOnce(); err != nil {
+				b.handleErr(err)
+			}
+		}
+	}
+}
+
+func (b *Bridge) pushOnce() error {
+	families, err := b.reg.Gather()
+	if err != nil {
+		return fmt.Errorf("gather: %w", err)
+	}
+
+	lines := renderLines(b.cfg.Prefix, families, time.Now().Unix())
+	if err := b.writeLines(lines); err != nil {
+		// The connection may be in an unknown state after a write error
+		// (e.g. the carbon endpoint closed it); drop it so the next push
+		// dials fresh rather than retrying a dead socket forever.
+		b.closeConn()
+		return fmt.Errorf("write: %w", err)
+	}
+	return nil
+}
+
+func (b *Bridge) writeLines(lines []string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	conn, err := b.ensureConnLocked()
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(conn)
+	for _, line := range lines {
+		if _, err := w.WriteString(line); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+func (b *Bridge) ensureConnLocked() (net.Conn, error) {
+	if b.conn != nil {
+		return b.conn, nil
+	}
+
+	conn, err := net.DialTimeout("tcp", b.cfg.URL, b.cfg.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("dial %s: %w", b.cfg.URL, err)
+	}
+	b.conn = conn
+	return conn, nil
+}
+
+func (b *Bridge) closeConn() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.conn != nil {
+		_ = b.conn.Close()
+		b.conn = nil
+	}
+}
+
+func (b *Bridge) handleErr(err error) {
+	if b.cfg.ErrorHandling == ErrorHandlingSilent {
+		return
+	}
+	log.Printf("graphite: push to %s failed: %v", b.cfg.URL, err)
+}
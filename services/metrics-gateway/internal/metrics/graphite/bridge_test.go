@@ -0,0 +1,125 @@
+package graphite
+
+import (
+	"bufio"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// fakeCarbonServer accepts TCP connections until closed and collects every
+// line written to any of them, mirroring the fake-server testing convention
+// used for the gRPC push path in internal/ingest.
+type fakeCarbonServer struct {
+	ln    net.Listener
+	lines chan string
+}
+
+func newFakeCarbonServer(t *testing.T) *fakeCarbonServer {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	s := &fakeCarbonServer{ln: ln, lines: make(chan string, 64)}
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					s.lines <- scanner.Text()
+				}
+			}(conn)
+		}
+	}()
+
+	return s
+}
+
+func (s *fakeCarbonServer) addr() string {
+	return s.ln.Addr().String()
+}
+
+func (s *fakeCarbonServer) close() {
+	s.ln.Close()
+}
+
+func (s *fakeCarbonServer) waitForLine(t *testing.T) string {
+	t.Helper()
+
+	select {
+	case line := <-s.lines:
+		return line
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for carbon line")
+		return ""
+	}
+}
+
+func TestBridgePushOnceWritesCarbonLines(t *testing.T) {
+	server := newFakeCarbonServer(t)
+	defer server.close()
+
+	reg := prometheus.NewRegistry()
+	counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_counter", Help: "test"})
+	counter.Add(5)
+	reg.MustRegister(counter)
+
+	cfg := Config{URL: server.addr(), Prefix: "mg", Timeout: time.Second}
+	b := NewBridge(cfg, reg)
+
+	if err := b.pushOnce(); err != nil {
+		t.Fatalf("pushOnce: %v", err)
+	}
+
+	line := server.waitForLine(t)
+	want := "mg.test_counter 5"
+	if len(line) < len(want) || line[:len(want)] != want {
+		t.Fatalf("unexpected line %q, want prefix %q", line, want)
+	}
+}
+
+func TestBridgeReconnectsAfterConnectionDrop(t *testing.T) {
+	server := newFakeCarbonServer(t)
+	defer server.close()
+
+	reg := prometheus.NewRegistry()
+	counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_counter", Help: "test"})
+	reg.MustRegister(counter)
+
+	cfg := Config{URL: server.addr(), Prefix: "mg", Timeout: time.Second}
+	b := NewBridge(cfg, reg)
+
+	if err := b.pushOnce(); err != nil {
+		t.Fatalf("first pushOnce: %v", err)
+	}
+	server.waitForLine(t)
+
+	b.closeConn()
+
+	if err := b.pushOnce(); err != nil {
+		t.Fatalf("second pushOnce after forced disconnect: %v", err)
+	}
+	server.waitForLine(t)
+}
+
+func TestBridgePushOnceDialErrorIsReturned(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	cfg := Config{URL: "127.0.0.1:1", Timeout: 50 * time.Millisecond}
+	b := NewBridge(cfg, reg)
+
+	if err := b.pushOnce(); err == nil {
+		t.Fatal("expected an error dialing an unreachable carbon endpoint")
+	}
+}
@@ -0,0 +1,85 @@
+// Package graphite pushes the samples gathered from a *prometheus.Registry
+// to a Graphite carbon endpoint using the plaintext line protocol, for
+// operators who already run a Graphite/StatsD pipeline and would rather not
+// stand up a Prometheus server just to scrape this gateway.
+package graphite
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrorHandling controls how a push failure (a Gather error or a write/dial
+// failure against the carbon endpoint) is reported from Bridge.Run's loop.
+type ErrorHandling int
+
+const (
+	// ErrorHandlingLog logs every push failure. This is the default: a
+	// misconfigured or unreachable carbon endpoint should be visible in the
+	// gateway's own logs rather than failing silently forever.
+	ErrorHandlingLog ErrorHandling = iota
+	// ErrorHandlingSilent drops push failures without logging, for
+	// deployments where the carbon endpoint is known to be flaky and the
+	// resulting log spam isn't useful.
+	ErrorHandlingSilent
+)
+
+func (e ErrorHandling) String() string {
+	if e == ErrorHandlingSilent {
+		return "silent"
+	}
+	return "log"
+}
+
+// Config configures a Bridge.
+type Config struct {
+	// URL is the carbon endpoint's host:port, dialed over TCP.
+	URL string
+	// Prefix is prepended to every metric path, e.g. "metrics_gateway".
+	Prefix string
+
+	Interval time.Duration
+	Timeout  time.Duration
+
+	ErrorHandling ErrorHandling
+}
+
+// Enabled reports whether a carbon endpoint was configured.
+func (c Config) Enabled() bool { return c.URL != "" }
+
+// LoadConfigFromEnv builds a Config from GRAPHITE_* environment variables.
+// An empty GRAPHITE_URL disables the bridge entirely.
+func LoadConfigFromEnv() Config {
+	return Config{
+		URL:           os.Getenv("GRAPHITE_URL"),
+		Prefix:        getenv("GRAPHITE_PREFIX", "metrics_gateway"),
+		Interval:      parseDurationMS(getenv("GRAPHITE_PUSH_INTERVAL_MS", "10000"), 10*time.Second),
+		Timeout:       parseDurationMS(getenv("GRAPHITE_DIAL_TIMEOUT_MS", "5000"), 5*time.Second),
+		ErrorHandling: parseErrorHandling(getenv("GRAPHITE_ERROR_HANDLING", "log")),
+	}
+}
+
+func parseErrorHandling(s string) ErrorHandling {
+	if strings.TrimSpace(s) == "silent" {
+		return ErrorHandlingSilent
+	}
+	return ErrorHandlingLog
+}
+
+func getenv(k, def string) string {
+	v := os.Getenv(k)
+	if v == "" {
+		return def
+	}
+	return v
+}
+
+func parseDurationMS(s string, def time.Duration) time.Duration {
+	ms, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || ms < 0 {
+		return def
+	}
+	return time.Duration(ms) * time.Millisecond
+}
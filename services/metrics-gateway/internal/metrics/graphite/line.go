@@ -0,0 +1,99 @@
+package graphite
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// unsafeComponentRe matches anything that isn't safe to embed in a
+// dot-separated Graphite metric path component.
+var unsafeComponentRe = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// sanitizeComponent replaces characters that would otherwise be read as path
+// separators or break the line protocol (dots, whitespace, semicolons, '=')
+// with underscores.
+func sanitizeComponent(s string) string {
+	if s == "" {
+		return "_"
+	}
+	return unsafeComponentRe.ReplaceAllString(s, "_")
+}
+
+// metricPath builds the dot-separated path for one labeled series, e.g.
+// disk_read_ops{disk_id="disk0"} with prefix "metrics_gateway" becomes
+// "metrics_gateway.disk_read_ops.disk0". Label names are dropped (Graphite
+// paths carry no key, only position), so label order must be stable; the
+// client_golang registry already gathers labels sorted by name.
+func metricPath(prefix, name string, labels []*dto.LabelPair) string {
+	parts := make([]string, 0, len(labels)+1)
+	parts = append(parts, name)
+	for _, lp := range labels {
+		parts = append(parts, sanitizeComponent(lp.GetValue()))
+	}
+
+	path := strings.Join(parts, ".")
+	if prefix != "" {
+		path = prefix + "." + path
+	}
+	return path
+}
+
+// renderLines flattens a Gather() result into carbon plaintext lines
+// ("path value timestamp\n"), expanding multi-sample metric types
+// (histograms, summaries) into one line per bucket/quantile plus _sum/_count.
+func renderLines(prefix string, families []*dto.MetricFamily, ts int64) []string {
+	var lines []string
+
+	for _, mf := range families {
+		for _, m := range mf.GetMetric() {
+			base := metricPath(prefix, mf.GetName(), m.GetLabel())
+
+			switch mf.GetType() {
+			case dto.MetricType_COUNTER:
+				lines = append(lines, carbonLine(base, m.GetCounter().GetValue(), ts))
+			case dto.MetricType_GAUGE:
+				lines = append(lines, carbonLine(base, m.GetGauge().GetValue(), ts))
+			case dto.MetricType_UNTYPED:
+				lines = append(lines, carbonLine(base, m.GetUntyped().GetValue(), ts))
+			case dto.MetricType_HISTOGRAM:
+				lines = append(lines, renderHistogram(base, m.GetHistogram(), ts)...)
+			case dto.MetricType_SUMMARY:
+				lines = append(lines, renderSummary(base, m.GetSummary(), ts)...)
+			}
+		}
+	}
+
+	return lines
+}
+
+func renderHistogram(base string, h *dto.Histogram, ts int64) []string {
+	lines := []string{
+		carbonLine(base+".sum", h.GetSampleSum(), ts),
+		carbonLine(base+".count", float64(h.GetSampleCount()), ts),
+	}
+	for _, b := range h.GetBucket() {
+		le := sanitizeComponent(strconv.FormatFloat(b.GetUpperBound(), 'g', -1, 64))
+		lines = append(lines, carbonLine(base+".bucket."+le, float64(b.GetCumulativeCount()), ts))
+	}
+	return lines
+}
+
+func renderSummary(base string, s *dto.Summary, ts int64) []string {
+	lines := []string{
+		carbonLine(base+".sum", s.GetSampleSum(), ts),
+		carbonLine(base+".count", float64(s.GetSampleCount()), ts),
+	}
+	for _, q := range s.GetQuantile() {
+		quantile := sanitizeComponent(strconv.FormatFloat(q.GetQuantile(), 'g', -1, 64))
+		lines = append(lines, carbonLine(base+".quantile."+quantile, q.GetValue(), ts))
+	}
+	return lines
+}
+
+func carbonLine(path string, value float64, ts int64) string {
+	return fmt.Sprintf("%s %s %d\n", path, strconv.FormatFloat(value, 'g', -1, 64), ts)
+}
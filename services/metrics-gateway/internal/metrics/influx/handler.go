@@ -0,0 +1,29 @@
+package influx
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// NewHTTPHandler returns the /telegraf handler: gathering reg and writing
+// its metrics out as InfluxDB line protocol.
+func NewHTTPHandler(reg prometheus.Gatherer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		families, err := reg.Gather()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		lines := renderLines(families, time.Now().UnixNano())
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write([]byte(strings.Join(lines, "\n")))
+		if len(lines) > 0 {
+			_, _ = w.Write([]byte("\n"))
+		}
+	})
+}
@@ -0,0 +1,156 @@
+// Package influx renders a *prometheus.Registry's gathered metrics as
+// InfluxDB line protocol, so Telegraf's inputs.http plugin can scrape this
+// gateway directly without a Prometheus intermediary.
+package influx
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+type tagPair struct {
+	key   string
+	value string
+}
+
+// lineGroup accumulates every field destined for one measurement+tags
+// line, so metrics that share a label set (e.g. disk_read_ops and
+// disk_read_bytes for the same disk_id) coalesce into a single line with
+// multiple fields instead of one line per metric.
+type lineGroup struct {
+	measurement string
+	tags        []tagPair
+	fields      map[string]float64
+}
+
+func newLineGroup(measurement string, tags []tagPair) *lineGroup {
+	return &lineGroup{measurement: measurement, tags: tags, fields: map[string]float64{}}
+}
+
+func (g *lineGroup) render(tsNanos int64) string {
+	var b strings.Builder
+
+	b.WriteString(escapeMeasurement(g.measurement))
+	for _, t := range g.tags {
+		b.WriteByte(',')
+		b.WriteString(escapeTag(t.key))
+		b.WriteByte('=')
+		b.WriteString(escapeTag(t.value))
+	}
+	b.WriteByte(' ')
+
+	keys := make([]string, 0, len(g.fields))
+	for k := range g.fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(escapeTag(k))
+		b.WriteByte('=')
+		b.WriteString(strconv.FormatFloat(g.fields[k], 'g', -1, 64))
+	}
+
+	b.WriteByte(' ')
+	b.WriteString(strconv.FormatInt(tsNanos, 10))
+	return b.String()
+}
+
+func escapeMeasurement(s string) string {
+	return strings.NewReplacer(",", "\\,", " ", "\\ ").Replace(s)
+}
+
+func escapeTag(s string) string {
+	return strings.NewReplacer(",", "\\,", " ", "\\ ", "=", "\\=").Replace(s)
+}
+
+// splitMetricName splits a metric name into its subsystem measurement (the
+// first underscore-delimited segment, e.g. "disk" for disk_read_ops) and
+// the remaining field key.
+func splitMetricName(name string) (measurement, field string) {
+	idx := strings.IndexByte(name, '_')
+	if idx < 0 {
+		return name, name
+	}
+	return name[:idx], name[idx+1:]
+}
+
+func sortedTags(labels []*dto.LabelPair) []tagPair {
+	tags := make([]tagPair, 0, len(labels))
+	for _, lp := range labels {
+		tags = append(tags, tagPair{key: lp.GetName(), value: lp.GetValue()})
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].key < tags[j].key })
+	return tags
+}
+
+func tagsKey(tags []tagPair) string {
+	parts := make([]string, len(tags))
+	for i, t := range tags {
+		parts[i] = t.key + "=" + t.value
+	}
+	return strings.Join(parts, ",")
+}
+
+// renderLines flattens a Gather() result into InfluxDB line-protocol
+// lines, coalescing every metric that shares a measurement and tag set
+// into one line. Histogram/summary samples expand into "<field>_sum" and
+// "<field>_count" fields on that same line rather than separate lines,
+// since they share the same tags as any sibling counter/gauge.
+func renderLines(families []*dto.MetricFamily, tsNanos int64) []string {
+	groups := map[string]*lineGroup{}
+
+	for _, mf := range families {
+		measurement, field := splitMetricName(mf.GetName())
+
+		for _, m := range mf.GetMetric() {
+			tags := sortedTags(m.GetLabel())
+			key := measurement + "\x00" + tagsKey(tags)
+
+			g, ok := groups[key]
+			if !ok {
+				g = newLineGroup(measurement, tags)
+				groups[key] = g
+			}
+
+			addFields(g, field, mf.GetType(), m)
+		}
+	}
+
+	keys := make([]string, 0, len(groups))
+	for k := range groups {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	lines := make([]string, 0, len(keys))
+	for _, k := range keys {
+		lines = append(lines, groups[k].render(tsNanos))
+	}
+	return lines
+}
+
+func addFields(g *lineGroup, field string, t dto.MetricType, m *dto.Metric) {
+	switch t {
+	case dto.MetricType_COUNTER:
+		g.fields[field] = m.GetCounter().GetValue()
+	case dto.MetricType_GAUGE:
+		g.fields[field] = m.GetGauge().GetValue()
+	case dto.MetricType_UNTYPED:
+		g.fields[field] = m.GetUntyped().GetValue()
+	case dto.MetricType_HISTOGRAM:
+		h := m.GetHistogram()
+		g.fields[field+"_sum"] = h.GetSampleSum()
+		g.fields[field+"_count"] = float64(h.GetSampleCount())
+	case dto.MetricType_SUMMARY:
+		s := m.GetSummary()
+		g.fields[field+"_sum"] = s.GetSampleSum()
+		g.fields[field+"_count"] = float64(s.GetSampleCount())
+	}
+}
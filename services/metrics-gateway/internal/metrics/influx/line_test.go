@@ -0,0 +1,91 @@
+package influx
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestRenderLinesCoalescesSharedLabelSet(t *testing.T) {
+	reg := prometheus.NewRegistry()
+
+	readOps := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "disk_read_ops", Help: "x"}, []string{"disk_id"})
+	readBytes := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "disk_read_bytes", Help: "x"}, []string{"disk_id"})
+	reg.MustRegister(readOps, readBytes)
+
+	readOps.WithLabelValues("disk0").Inc()
+	readBytes.WithLabelValues("disk0").Add(4096)
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+
+	lines := renderLines(families, 123)
+	if len(lines) != 1 {
+		t.Fatalf("expected metrics sharing a label set to coalesce into one line, got %d: %v", len(lines), lines)
+	}
+
+	want := "disk,disk_id=disk0 read_bytes=4096,read_ops=1 123"
+	if lines[0] != want {
+		t.Fatalf("line = %q, want %q", lines[0], want)
+	}
+}
+
+func TestRenderLinesSeparatesDifferentLabelSets(t *testing.T) {
+	reg := prometheus.NewRegistry()
+
+	readOps := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "disk_read_ops", Help: "x"}, []string{"disk_id"})
+	reg.MustRegister(readOps)
+
+	readOps.WithLabelValues("disk0").Inc()
+	readOps.WithLabelValues("disk1").Add(2)
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+
+	lines := renderLines(families, 0)
+	if len(lines) != 2 {
+		t.Fatalf("expected one line per distinct label set, got %d: %v", len(lines), lines)
+	}
+}
+
+func TestRenderLinesHistogramExpandsToSumAndCount(t *testing.T) {
+	reg := prometheus.NewRegistry()
+
+	latency := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "disk_read_latency_seconds",
+		Help:    "x",
+		Buckets: []float64{0.001, 0.01},
+	}, []string{"disk_id"})
+	reg.MustRegister(latency)
+
+	latency.WithLabelValues("disk0").Observe(0.002)
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+
+	lines := renderLines(families, 456)
+	want := "disk,disk_id=disk0 read_latency_seconds_count=1,read_latency_seconds_sum=0.002 456"
+	if len(lines) != 1 || lines[0] != want {
+		t.Fatalf("lines = %v, want [%q]", lines, want)
+	}
+}
+
+func TestSplitMetricName(t *testing.T) {
+	cases := map[string][2]string{
+		"disk_read_ops": {"disk", "read_ops"},
+		"fuse_errors":   {"fuse", "errors"},
+		"noseparator":   {"noseparator", "noseparator"},
+	}
+	for name, want := range cases {
+		measurement, field := splitMetricName(name)
+		if measurement != want[0] || field != want[1] {
+			t.Errorf("splitMetricName(%q) = (%q, %q), want (%q, %q)", name, measurement, field, want[0], want[1])
+		}
+	}
+}
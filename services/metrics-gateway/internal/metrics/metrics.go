@@ -3,6 +3,8 @@ package metrics
 import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors"
+
+	"metrics-gateway/internal/ingest/wal"
 )
 
 type DiskMetrics struct {
@@ -10,39 +12,51 @@ type DiskMetrics struct {
 	WriteOps     *prometheus.CounterVec
 	ReadBytes    *prometheus.CounterVec
 	WriteBytes   *prometheus.CounterVec
-	ReadLatency  *prometheus.GaugeVec
-	WriteLatency *prometheus.GaugeVec
-	QueueDepth   *prometheus.GaugeVec
-	Errors       *prometheus.CounterVec
+	ReadLatency  *prometheus.HistogramVec
+	WriteLatency *prometheus.HistogramVec
+	// ReadLatencyLastSeconds/WriteLatencyLastSeconds hold a single latency
+	// sample, for dashboards and alert rules written against the old
+	// pre-histogram Gauges. The Simulator sets them each tick to the last
+	// sample it observed into ReadLatency/WriteLatency; the ingest path,
+	// HostCollector and Replayer observe into the histograms without
+	// updating them.
+	ReadLatencyLastSeconds  *prometheus.GaugeVec
+	WriteLatencyLastSeconds *prometheus.GaugeVec
+	QueueDepth              *prometheus.GaugeVec
+	Errors                  *prometheus.CounterVec
 }
 
 type RaidMetrics struct {
-	ReadOps            *prometheus.CounterVec
-	WriteOps           *prometheus.CounterVec
-	ReadBytes          *prometheus.CounterVec
-	WriteBytes         *prometheus.CounterVec
-	ReadLatency        *prometheus.GaugeVec
-	WriteLatency       *prometheus.GaugeVec
-	Raid1ReadsFromDisk *prometheus.CounterVec
-	Raid1Resync        *prometheus.GaugeVec
-	Raid3ParityReads   *prometheus.CounterVec
-	Raid3ParityWrites  *prometheus.CounterVec
-	Raid3PartialStripe *prometheus.CounterVec
-	DegradedState      *prometheus.GaugeVec
-	FailedDisks        *prometheus.GaugeVec
-	RebuildInProgress  *prometheus.GaugeVec
+	ReadOps                 *prometheus.CounterVec
+	WriteOps                *prometheus.CounterVec
+	ReadBytes               *prometheus.CounterVec
+	WriteBytes              *prometheus.CounterVec
+	ReadLatency             *prometheus.HistogramVec
+	WriteLatency            *prometheus.HistogramVec
+	ReadLatencyLastSeconds  *prometheus.GaugeVec
+	WriteLatencyLastSeconds *prometheus.GaugeVec
+	Raid1ReadsFromDisk      *prometheus.CounterVec
+	Raid1Resync             *prometheus.GaugeVec
+	Raid3ParityReads        *prometheus.CounterVec
+	Raid3ParityWrites       *prometheus.CounterVec
+	Raid3PartialStripe      *prometheus.CounterVec
+	DegradedState           *prometheus.GaugeVec
+	FailedDisks             *prometheus.GaugeVec
+	RebuildInProgress       *prometheus.GaugeVec
 }
 
 type FuseMetrics struct {
-	ReadOps      prometheus.Counter
-	WriteOps     prometheus.Counter
-	OpenOps      prometheus.Counter
-	FsyncOps     prometheus.Counter
-	ReadBytes    prometheus.Counter
-	WriteBytes   prometheus.Counter
-	ReadLatency  prometheus.Gauge
-	WriteLatency prometheus.Gauge
-	Errors       prometheus.Counter
+	ReadOps                 prometheus.Counter
+	WriteOps                prometheus.Counter
+	OpenOps                 prometheus.Counter
+	FsyncOps                prometheus.Counter
+	ReadBytes               prometheus.Counter
+	WriteBytes              prometheus.Counter
+	ReadLatency             prometheus.Histogram
+	WriteLatency            prometheus.Histogram
+	ReadLatencyLastSeconds  prometheus.Gauge
+	WriteLatencyLastSeconds prometheus.Gauge
+	Errors                  prometheus.Counter
 }
 
 type ProcessMetrics struct {
@@ -50,14 +64,44 @@ type ProcessMetrics struct {
 	ResidentMemory prometheus.Gauge
 }
 
+// BenchMetrics holds the most recent self-benchmark scores (see
+// internal/bench), each a unit-free ratio against a calibrated reference
+// constant, plus their mean. They're only updated when something triggers
+// a run (the /bench HTTP handler), so a deployment that never hits that
+// endpoint simply reports these as 0.
+type BenchMetrics struct {
+	Disk      prometheus.Gauge
+	Raid      prometheus.Gauge
+	Fuse      prometheus.Gauge
+	Aggregate prometheus.Gauge
+}
+
 type AllMetrics struct {
 	Disks   *DiskMetrics
 	Raid    *RaidMetrics
 	Fuse    *FuseMetrics
 	Process *ProcessMetrics
+	Bench   *BenchMetrics
+	Wal     wal.Metrics
+}
+
+// LatencyBuckets overrides the default per-tier histogram bucket boundaries;
+// a nil field keeps that tier's default buckets (diskLatencyBuckets,
+// raidLatencyBuckets, fuseLatencyBuckets).
+type LatencyBuckets struct {
+	Disk []float64
+	Raid []float64
+	Fuse []float64
 }
 
 func NewMetricsRegistry() (*prometheus.Registry, *AllMetrics) {
+	return NewMetricsRegistryWithBuckets(LatencyBuckets{})
+}
+
+// NewMetricsRegistryWithBuckets is NewMetricsRegistry with the latency
+// histogram bucket boundaries overridden per tier, for scenarios that need
+// buckets tuned to a particular deployment's expected latency range.
+func NewMetricsRegistryWithBuckets(b LatencyBuckets) (*prometheus.Registry, *AllMetrics) {
 	reg := prometheus.NewRegistry()
 
 	reg.MustRegister(
@@ -65,11 +109,24 @@ func NewMetricsRegistry() (*prometheus.Registry, *AllMetrics) {
 		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
 	)
 
+	diskBuckets, raidBuckets, fuseBuckets := diskLatencyBuckets, raidLatencyBuckets, fuseLatencyBuckets
+	if b.Disk != nil {
+		diskBuckets = b.Disk
+	}
+	if b.Raid != nil {
+		raidBuckets = b.Raid
+	}
+	if b.Fuse != nil {
+		fuseBuckets = b.Fuse
+	}
+
 	all := &AllMetrics{
-		Disks:   NewDiskMetrics(reg),
-		Raid:    NewRaidMetrics(reg),
-		Fuse:    NewFuseMetrics(reg),
+		Disks:   NewDiskMetrics(reg, diskBuckets),
+		Raid:    NewRaidMetrics(reg, raidBuckets),
+		Fuse:    NewFuseMetrics(reg, fuseBuckets),
 		Process: NewProcessMetrics(reg),
+		Bench:   NewBenchMetrics(reg),
+		Wal:     NewWALMetrics(reg),
 	}
 
 	return reg, all
@@ -87,6 +144,45 @@ func newCounterVec(reg prometheus.Registerer, name, help string, labels ...strin
 	return cv
 }
 
+// diskLatencyBuckets covers typical spinning/SSD disk I/O latencies, from
+// sub-millisecond SSD reads out to multi-second tail latency under load.
+var diskLatencyBuckets = prometheus.ExponentialBuckets(0.0001, 2, 18)
+
+// raidLatencyBuckets is shifted wider than diskLatencyBuckets since a RAID
+// stripe write can fan out to several disks and a degraded-array rebuild
+// read, so its tail is longer than any single disk's.
+var raidLatencyBuckets = prometheus.ExponentialBuckets(0.0002, 2, 19)
+
+// fuseLatencyBuckets is the narrowest of the three: FUSE metadata ops
+// (read/write passed straight through to the kernel) are expected to
+// complete in microseconds to low milliseconds in the common case.
+var fuseLatencyBuckets = prometheus.ExponentialBuckets(0.00005, 2, 16)
+
+func newHistogramVec(reg prometheus.Registerer, name, help string, buckets []float64, labels ...string) *prometheus.HistogramVec {
+	hv := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    name,
+			Help:    help,
+			Buckets: buckets,
+		},
+		labels,
+	)
+	reg.MustRegister(hv)
+	return hv
+}
+
+func newHistogram(reg prometheus.Registerer, name, help string, buckets []float64) prometheus.Histogram {
+	h := prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    name,
+			Help:    help,
+			Buckets: buckets,
+		},
+	)
+	reg.MustRegister(h)
+	return h
+}
+
 func newGaugeVec(reg prometheus.Registerer, name, help string, labels ...string) *prometheus.GaugeVec {
 	gv := prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
@@ -121,49 +217,55 @@ func newGauge(reg prometheus.Registerer, name, help string) prometheus.Gauge {
 	return g
 }
 
-func NewDiskMetrics(reg prometheus.Registerer) *DiskMetrics {
+func NewDiskMetrics(reg prometheus.Registerer, buckets []float64) *DiskMetrics {
 	return &DiskMetrics{
-		ReadOps:      newCounterVec(reg, "disk_read_ops", "Number of disk read operations", "disk_id"),
-		WriteOps:     newCounterVec(reg, "disk_write_ops", "Number of disk write operations", "disk_id"),
-		ReadBytes:    newCounterVec(reg, "disk_read_bytes", "Bytes read from disk", "disk_id"),
-		WriteBytes:   newCounterVec(reg, "disk_write_bytes", "Bytes written to disk", "disk_id"),
-		ReadLatency:  newGaugeVec(reg, "disk_read_latency", "Average disk read latency (seconds)", "disk_id"),
-		WriteLatency: newGaugeVec(reg, "disk_write_latency", "Average disk write latency (seconds)", "disk_id"),
-		QueueDepth:   newGaugeVec(reg, "disk_queue_depth", "Current disk queue depth", "disk_id"),
-		Errors:       newCounterVec(reg, "disk_errors", "Total disk errors", "disk_id"),
+		ReadOps:                 newCounterVec(reg, "disk_read_ops", "Number of disk read operations", "disk_id"),
+		WriteOps:                newCounterVec(reg, "disk_write_ops", "Number of disk write operations", "disk_id"),
+		ReadBytes:               newCounterVec(reg, "disk_read_bytes", "Bytes read from disk", "disk_id"),
+		WriteBytes:              newCounterVec(reg, "disk_write_bytes", "Bytes written to disk", "disk_id"),
+		ReadLatency:             newHistogramVec(reg, "disk_read_latency_seconds", "Disk read latency distribution (seconds)", buckets, "disk_id"),
+		WriteLatency:            newHistogramVec(reg, "disk_write_latency_seconds", "Disk write latency distribution (seconds)", buckets, "disk_id"),
+		ReadLatencyLastSeconds:  newGaugeVec(reg, "disk_read_latency_last_seconds", "Most recently observed disk read latency (seconds)", "disk_id"),
+		WriteLatencyLastSeconds: newGaugeVec(reg, "disk_write_latency_last_seconds", "Most recently observed disk write latency (seconds)", "disk_id"),
+		QueueDepth:              newGaugeVec(reg, "disk_queue_depth", "Current disk queue depth", "disk_id"),
+		Errors:                  newCounterVec(reg, "disk_errors", "Total disk errors", "disk_id"),
 	}
 }
 
-func NewRaidMetrics(reg prometheus.Registerer) *RaidMetrics {
+func NewRaidMetrics(reg prometheus.Registerer, buckets []float64) *RaidMetrics {
 	return &RaidMetrics{
-		ReadOps:            newCounterVec(reg, "raid_read_ops", "Total RAID read operations", "raid"),
-		WriteOps:           newCounterVec(reg, "raid_write_ops", "Total RAID write operations", "raid"),
-		ReadBytes:          newCounterVec(reg, "raid_read_bytes", "Total RAID read bytes", "raid"),
-		WriteBytes:         newCounterVec(reg, "raid_write_bytes", "Total RAID write bytes", "raid"),
-		ReadLatency:        newGaugeVec(reg, "raid_read_latency", "Average RAID read latency (seconds)", "raid"),
-		WriteLatency:       newGaugeVec(reg, "raid_write_latency", "Average RAID write latency (seconds)", "raid"),
-		Raid1ReadsFromDisk: newCounterVec(reg, "raid1_reads_from_disk", "Reads served from a given disk in RAID1", "raid", "disk_id"),
-		Raid1Resync:        newGaugeVec(reg, "raid1_resync_progress", "RAID1 resync progress (0-1)", "raid"),
-		Raid3ParityReads:   newCounterVec(reg, "raid3_parity_reads", "RAID3 parity read operations", "raid"),
-		Raid3ParityWrites:  newCounterVec(reg, "raid3_parity_writes", "RAID3 parity write operations", "raid"),
-		Raid3PartialStripe: newCounterVec(reg, "raid3_partial_stripe_writes", "RAID3 partial stripe writes", "raid"),
-		DegradedState:      newGaugeVec(reg, "raid_degraded_state", "RAID degraded state (0/1)", "raid"),
-		FailedDisks:        newGaugeVec(reg, "raid_failed_disks", "Number of failed disks in RAID", "raid"),
-		RebuildInProgress:  newGaugeVec(reg, "raid_rebuild_in_progress", "RAID rebuild in progress (0/1)", "raid"),
+		ReadOps:                 newCounterVec(reg, "raid_read_ops", "Total RAID read operations", "raid"),
+		WriteOps:                newCounterVec(reg, "raid_write_ops", "Total RAID write operations", "raid"),
+		ReadBytes:               newCounterVec(reg, "raid_read_bytes", "Total RAID read bytes", "raid"),
+		WriteBytes:              newCounterVec(reg, "raid_write_bytes", "Total RAID write bytes", "raid"),
+		ReadLatency:             newHistogramVec(reg, "raid_read_latency_seconds", "RAID read latency distribution (seconds)", buckets, "raid"),
+		WriteLatency:            newHistogramVec(reg, "raid_write_latency_seconds", "RAID write latency distribution (seconds)", buckets, "raid"),
+		ReadLatencyLastSeconds:  newGaugeVec(reg, "raid_read_latency_last_seconds", "Most recently observed RAID read latency (seconds)", "raid"),
+		WriteLatencyLastSeconds: newGaugeVec(reg, "raid_write_latency_last_seconds", "Most recently observed RAID write latency (seconds)", "raid"),
+		Raid1ReadsFromDisk:      newCounterVec(reg, "raid1_reads_from_disk", "Reads served from a given disk in RAID1", "raid", "disk_id"),
+		Raid1Resync:             newGaugeVec(reg, "raid1_resync_progress", "RAID1 resync progress (0-1)", "raid"),
+		Raid3ParityReads:        newCounterVec(reg, "raid3_parity_reads", "RAID3 parity read operations", "raid"),
+		Raid3ParityWrites:       newCounterVec(reg, "raid3_parity_writes", "RAID3 parity write operations", "raid"),
+		Raid3PartialStripe:      newCounterVec(reg, "raid3_partial_stripe_writes", "RAID3 partial stripe writes", "raid"),
+		DegradedState:           newGaugeVec(reg, "raid_degraded_state", "RAID degraded state (0/1)", "raid"),
+		FailedDisks:             newGaugeVec(reg, "raid_failed_disks", "Number of failed disks in RAID", "raid"),
+		RebuildInProgress:       newGaugeVec(reg, "raid_rebuild_in_progress", "RAID rebuild in progress (0/1)", "raid"),
 	}
 }
 
-func NewFuseMetrics(reg prometheus.Registerer) *FuseMetrics {
+func NewFuseMetrics(reg prometheus.Registerer, buckets []float64) *FuseMetrics {
 	return &FuseMetrics{
-		ReadOps:      newCounter(reg, "fuse_read_ops", "Number of FUSE read operations"),
-		WriteOps:     newCounter(reg, "fuse_write_ops", "Number of FUSE write operations"),
-		OpenOps:      newCounter(reg, "fuse_open_ops", "Number of FUSE open operations"),
-		FsyncOps:     newCounter(reg, "fuse_fsync_ops", "Number of FUSE fsync operations"),
-		ReadBytes:    newCounter(reg, "fuse_read_bytes", "Bytes read via FUSE"),
-		WriteBytes:   newCounter(reg, "fuse_write_bytes", "Bytes written via FUSE"),
-		ReadLatency:  newGauge(reg, "fuse_read_latency", "Average FUSE read latency (seconds)"),
-		WriteLatency: newGauge(reg, "fuse_write_latency", "Average FUSE write latency (seconds)"),
-		Errors:       newCounter(reg, "fuse_errors", "Total FUSE errors"),
+		ReadOps:                 newCounter(reg, "fuse_read_ops", "Number of FUSE read operations"),
+		WriteOps:                newCounter(reg, "fuse_write_ops", "Number of FUSE write operations"),
+		OpenOps:                 newCounter(reg, "fuse_open_ops", "Number of FUSE open operations"),
+		FsyncOps:                newCounter(reg, "fuse_fsync_ops", "Number of FUSE fsync operations"),
+		ReadBytes:               newCounter(reg, "fuse_read_bytes", "Bytes read via FUSE"),
+		WriteBytes:              newCounter(reg, "fuse_write_bytes", "Bytes written via FUSE"),
+		ReadLatency:             newHistogram(reg, "fuse_read_latency_seconds", "FUSE read latency distribution (seconds)", buckets),
+		WriteLatency:            newHistogram(reg, "fuse_write_latency_seconds", "FUSE write latency distribution (seconds)", buckets),
+		ReadLatencyLastSeconds:  newGauge(reg, "fuse_read_latency_last_seconds", "Most recently observed FUSE read latency (seconds)"),
+		WriteLatencyLastSeconds: newGauge(reg, "fuse_write_latency_last_seconds", "Most recently observed FUSE write latency (seconds)"),
+		Errors:                  newCounter(reg, "fuse_errors", "Total FUSE errors"),
 	}
 }
 
@@ -173,3 +275,20 @@ func NewProcessMetrics(reg prometheus.Registerer) *ProcessMetrics {
 		ResidentMemory: newGauge(reg, "process_resident_memory", "Simulated resident memory (bytes)"),
 	}
 }
+
+func NewBenchMetrics(reg prometheus.Registerer) *BenchMetrics {
+	return &BenchMetrics{
+		Disk:      newGauge(reg, "bench_disk_score", "Most recent self-benchmark disk score (1.0 == reference constant)"),
+		Raid:      newGauge(reg, "bench_raid_score", "Most recent self-benchmark RAID score (1.0 == reference constant)"),
+		Fuse:      newGauge(reg, "bench_fuse_score", "Most recent self-benchmark FUSE score (1.0 == reference constant)"),
+		Aggregate: newGauge(reg, "bench_aggregate_score", "Mean of the disk/raid/fuse self-benchmark scores"),
+	}
+}
+
+func NewWALMetrics(reg prometheus.Registerer) wal.Metrics {
+	return wal.Metrics{
+		DepthBytes:          newGauge(reg, "ingest_wal_depth_bytes", "Total size of unreplayed WAL segments (bytes)"),
+		SegmentCount:        newGauge(reg, "ingest_wal_segment_count", "Number of WAL segments on disk"),
+		FsyncLatencySeconds: newGauge(reg, "ingest_wal_fsync_latency_seconds", "Duration of the most recent WAL fsync (seconds)"),
+	}
+}
@@ -2,6 +2,8 @@ package metrics
 
 import (
 	"testing"
+
+	dto "github.com/prometheus/client_model/go"
 )
 
 func TestNewMetricsRegistryInitializesAllGroups(t *testing.T) {
@@ -26,14 +28,46 @@ func TestNewMetricsRegistryInitializesAllGroups(t *testing.T) {
 	if all.Process == nil {
 		t.Fatal("expected process metrics to be initialized")
 	}
+	if all.Bench == nil {
+		t.Fatal("expected bench metrics to be initialized")
+	}
 
 	all.Disks.ReadOps.WithLabelValues("disk0").Inc()
 	all.Disks.WriteOps.WithLabelValues("disk0").Add(5)
 	all.Raid.ReadOps.WithLabelValues("raid0").Add(10)
 	all.Fuse.ReadOps.Inc()
 	all.Process.CPUSeconds.Set(1.23)
+	all.Bench.Aggregate.Set(0.9)
+
+	all.Disks.ReadLatency.WithLabelValues("disk0").Observe(0.002)
+	all.Disks.ReadLatencyLastSeconds.WithLabelValues("disk0").Set(0.002)
+	all.Fuse.ReadLatency.Observe(0.0005)
+	all.Fuse.ReadLatencyLastSeconds.Set(0.0005)
 
 	if _, err := reg.Gather(); err != nil {
 		t.Fatalf("gather on registry failed: %v", err)
 	}
 }
+
+func TestNewMetricsRegistryWithBucketsOverridesDefaults(t *testing.T) {
+	custom := []float64{0.001, 0.01, 0.1}
+
+	_, all := NewMetricsRegistryWithBuckets(LatencyBuckets{Disk: custom})
+
+	all.Disks.ReadLatency.WithLabelValues("disk0").Observe(0.005)
+
+	metric := &dto.Metric{}
+	if err := all.Disks.ReadLatency.WithLabelValues("disk0").Write(metric); err != nil {
+		t.Fatalf("write metric: %v", err)
+	}
+
+	buckets := metric.GetHistogram().GetBucket()
+	if len(buckets) != len(custom) {
+		t.Fatalf("expected %d buckets, got %d", len(custom), len(buckets))
+	}
+	for i, b := range buckets {
+		if b.GetUpperBound() != custom[i] {
+			t.Errorf("bucket %d = %f, want %f", i, b.GetUpperBound(), custom[i])
+		}
+	}
+}
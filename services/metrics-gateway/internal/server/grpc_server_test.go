@@ -0,0 +1,467 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"metrics-gateway/internal/ingest"
+	"metrics-gateway/internal/metrics"
+	pb "metrics-gateway/internal/pb/metrics/v1"
+	"metrics-gateway/internal/server/quota"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+type fakeIngestServer struct {
+	pb.UnimplementedMetricsIngestorServer
+}
+
+type fakeServerStream struct {
+	ctx context.Context
+}
+
+func (f *fakeServerStream) SetHeader(metadata.MD) error  { return nil }
+func (f *fakeServerStream) SendHeader(metadata.MD) error { return nil }
+func (f *fakeServerStream) SetTrailer(metadata.MD)       {}
+func (f *fakeServerStream) Context() context.Context     { return f.ctx }
+func (f *fakeServerStream) SendMsg(any) error            { return nil }
+func (f *fakeServerStream) RecvMsg(any) error            { return nil }
+
+func TestParseHelpers(t *testing.T) {
+	if v := parseInt("not-a-number", 5); v != 5 {
+		t.Fatalf("expected parseInt fallback, got %d", v)
+	}
+	if v := parseFloat("bad", 1.5); v != 1.5 {
+		t.Fatalf("expected parseFloat fallback, got %f", v)
+	}
+	if v := parseDurationMS("-1", 2*time.Second); v != 2*time.Second {
+		t.Fatalf("expected parseDurationMS fallback, got %s", v)
+	}
+	if v := parseFileMode("bad", 0640); v != 0640 {
+		t.Fatalf("expected parseFileMode fallback, got %v", v)
+	}
+}
+
+func TestAuthInterceptors(t *testing.T) {
+	token := "secret"
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-metrics-token", token))
+
+	unary := unaryAuthInterceptor(token)
+	if _, err := unary(ctx, nil, nil, func(ctx context.Context, req any) (any, error) {
+		return "ok", nil
+	}); err != nil {
+		t.Fatalf("expected unary auth to pass: %v", err)
+	}
+
+	stream := streamAuthInterceptor(token)
+	ss := &fakeServerStream{ctx: ctx}
+	if err := stream(nil, ss, nil, func(srv any, stream grpc.ServerStream) error { return nil }); err != nil {
+		t.Fatalf("expected stream auth to pass: %v", err)
+	}
+
+	denyCtx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-metrics-token", "bad"))
+	if _, err := unary(denyCtx, nil, nil, func(ctx context.Context, req any) (any, error) {
+		return nil, nil
+	}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected unauthenticated, got %v", err)
+	}
+}
+
+func TestCheckAuthAcceptsVerifiedPeerIdentityWithoutToken(t *testing.T) {
+	ctx := withPeerIdentity(context.Background(), "ingest-client.internal")
+	if !checkAuth(ctx, "secret") {
+		t.Fatal("expected checkAuth to accept a verified peer identity in lieu of the bearer token")
+	}
+}
+
+func TestCheckAuthRejectsMissingTokenAndIdentity(t *testing.T) {
+	if checkAuth(context.Background(), "secret") {
+		t.Fatal("expected checkAuth to reject a request with neither token nor peer identity")
+	}
+}
+
+func TestCheckAuthAcceptsVerifiedPeerCredWithoutToken(t *testing.T) {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{AuthInfo: PeerCredInfo{UID: 1000, GID: 1000}})
+	if !checkAuth(ctx, "secret") {
+		t.Fatal("expected checkAuth to accept a verified UDS peer credential in lieu of the bearer token")
+	}
+}
+
+func TestParseUint32List(t *testing.T) {
+	if got := parseUint32List(""); got != nil {
+		t.Fatalf("expected nil for empty input, got %v", got)
+	}
+	got := parseUint32List("0, 1000,bad,1001")
+	want := []uint32{0, 1000, 1001}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+}
+
+func TestRateInterceptors(t *testing.T) {
+	ctx := context.Background()
+	registry := quota.NewKeyedRegistry(10, 0, quota.Limits{RPS: 0, Burst: 1}, "test_conn", "identity", nil)
+
+	unary := unaryRateInterceptor(registry, "global")
+	if _, err := unary(ctx, nil, nil, func(ctx context.Context, req any) (any, error) {
+		return nil, nil
+	}); err != nil {
+		t.Fatalf("expected first request within burst to pass, got %v", err)
+	}
+	if _, err := unary(ctx, nil, nil, func(ctx context.Context, req any) (any, error) {
+		return nil, nil
+	}); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected rate limited error, got %v", err)
+	}
+
+	streamRegistry := quota.NewKeyedRegistry(10, 0, quota.Limits{RPS: 0, Burst: 0}, "test_conn_stream", "identity", nil)
+	stream := streamRateInterceptor(streamRegistry, "global")
+	ss := &fakeServerStream{ctx: ctx}
+	if err := stream(nil, ss, nil, func(srv any, stream grpc.ServerStream) error {
+		return stream.RecvMsg(nil)
+	}); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected rate limited stream error, got %v", err)
+	}
+}
+
+func TestRateLimitIdentity(t *testing.T) {
+	if id := rateLimitIdentity(context.Background(), "global"); id != "global" {
+		t.Fatalf("expected global scope to return a constant key, got %q", id)
+	}
+
+	peerCtx := withPeerIdentity(context.Background(), "producer-a")
+	if id := rateLimitIdentity(peerCtx, "peer"); id != "producer-a" {
+		t.Fatalf("expected peer scope to return the verified identity, got %q", id)
+	}
+	if id := rateLimitIdentity(context.Background(), "peer"); id != "uds-peer" {
+		t.Fatalf("expected peer scope without a certificate to fall back, got %q", id)
+	}
+
+	tokenCtx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-metrics-token", "secret"))
+	if id := rateLimitIdentity(tokenCtx, "token"); id != "secret" {
+		t.Fatalf("expected token scope to return the bearer token, got %q", id)
+	}
+}
+
+func TestLoadGRPCConfigFromEnv(t *testing.T) {
+	t.Setenv("GRPC_UDS_PATH", filepath.Join(t.TempDir(), "srv.sock"))
+	t.Setenv("GRPC_UDS_SOCKET_MODE", "600")
+	t.Setenv("GRPC_MAX_RECV_BYTES", "2048")
+	t.Setenv("GRPC_MAX_CONCURRENT_STREAMS", "5")
+	t.Setenv("GRPC_AUTH_TOKEN", "tok")
+	t.Setenv("GRPC_RATELIMIT_RPS", "2.5")
+	t.Setenv("GRPC_RATELIMIT_BURST", "3")
+	t.Setenv("GRPC_RATELIMIT_SCOPE", "token")
+	t.Setenv("GRPC_RATELIMIT_MAX_IDENTITIES", "500")
+	t.Setenv("GRPC_RATELIMIT_IDLE_TTL_MS", "60000")
+	t.Setenv("GRPC_KA_MIN_TIME_MS", "10")
+	t.Setenv("GRPC_KA_TIME_MS", "11")
+	t.Setenv("GRPC_KA_TIMEOUT_MS", "12")
+	t.Setenv("GRPC_MAX_CONN_IDLE_MS", "13")
+	t.Setenv("GRPC_MAX_CONN_AGE_MS", "14")
+	t.Setenv("GRPC_MAX_CONN_AGE_GRACE_MS", "15")
+	t.Setenv("GRPC_QUOTA_RPS", "7.5")
+	t.Setenv("GRPC_QUOTA_BURST", "20")
+	t.Setenv("GRPC_QUOTA_BYTES_PER_SEC", "1024")
+	t.Setenv("GRPC_QUOTA_MAX_SOURCES", "50")
+	t.Setenv("GRPC_TCP_ADDR", ":9443")
+	t.Setenv("GRPC_TLS_CERT_FILE", "/tmp/cert.pem")
+	t.Setenv("GRPC_TLS_KEY_FILE", "/tmp/key.pem")
+	t.Setenv("GRPC_TLS_CLIENT_CA_FILE", "/tmp/ca.pem")
+	t.Setenv("GRPC_TLS_MIN_VERSION", "1.3")
+	t.Setenv("GRPC_TLS_REQUIRE_CLIENT_CERT", "true")
+	t.Setenv("GRPC_TLS_ALLOWED_IDENTITIES", "producer-a,producer-b")
+	t.Setenv("GRPC_UDS_ALLOWED_UIDS", "0,1000")
+	t.Setenv("GRPC_UDS_ALLOWED_GIDS", "1000")
+
+	cfg, err := LoadGRPCConfigFromEnv()
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+
+	if cfg.MaxRecvBytes != 2048 || cfg.MaxConcurrentStreams != 5 {
+		t.Fatalf("unexpected limits: %+v", cfg)
+	}
+	if cfg.AuthToken != "tok" || cfg.RateLimitRPS != 2.5 || cfg.RateLimitBurst != 3 {
+		t.Fatalf("unexpected auth/limit: %+v", cfg)
+	}
+	if cfg.RateLimitScope != "token" || cfg.RateLimitMaxIdentities != 500 || cfg.RateLimitIdleTTL != 60*time.Second {
+		t.Fatalf("unexpected rate limit scope/identity config: %+v", cfg)
+	}
+	if cfg.SocketMode != 0600 {
+		t.Fatalf("unexpected socket mode: %v", cfg.SocketMode)
+	}
+	if cfg.KAEnforcementMinTime != 10*time.Millisecond || cfg.KATime != 11*time.Millisecond ||
+		cfg.KATimeout != 12*time.Millisecond || cfg.MaxConnIdle != 13*time.Millisecond ||
+		cfg.MaxConnAge != 14*time.Millisecond || cfg.MaxConnAgeGrace != 15*time.Millisecond {
+		t.Fatalf("unexpected keepalive durations: %+v", cfg)
+	}
+	if cfg.QuotaDefaultRPS != 7.5 || cfg.QuotaDefaultBurst != 20 || cfg.QuotaDefaultBytesPerSecond != 1024 || cfg.QuotaMaxSources != 50 {
+		t.Fatalf("unexpected quota config: %+v", cfg)
+	}
+	if cfg.TCP.Addr != ":9443" || cfg.TCP.CertFile != "/tmp/cert.pem" || cfg.TCP.KeyFile != "/tmp/key.pem" || cfg.TCP.ClientCAFile != "/tmp/ca.pem" {
+		t.Fatalf("unexpected tcp/tls config: %+v", cfg.TCP)
+	}
+	if cfg.TCP.MinVersion != tls.VersionTLS13 || !cfg.TCP.RequireClientCert {
+		t.Fatalf("unexpected tls version/require: %+v", cfg.TCP)
+	}
+	if len(cfg.TCP.AllowedIdentities) != 2 || cfg.TCP.AllowedIdentities[0] != "producer-a" || cfg.TCP.AllowedIdentities[1] != "producer-b" {
+		t.Fatalf("unexpected allowed identities: %v", cfg.TCP.AllowedIdentities)
+	}
+	if len(cfg.UDSAllowedUIDs) != 2 || cfg.UDSAllowedUIDs[0] != 0 || cfg.UDSAllowedUIDs[1] != 1000 {
+		t.Fatalf("unexpected uds allowed uids: %v", cfg.UDSAllowedUIDs)
+	}
+	if len(cfg.UDSAllowedGIDs) != 1 || cfg.UDSAllowedGIDs[0] != 1000 {
+		t.Fatalf("unexpected uds allowed gids: %v", cfg.UDSAllowedGIDs)
+	}
+}
+
+func TestEnsureSocketDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "nested")
+	path := filepath.Join(dir, "sock")
+	if err := ensureSocketDir(path); err != nil {
+		t.Fatalf("ensureSocketDir: %v", err)
+	}
+	if err := ensureSocketDir("/"); err != nil {
+		t.Fatalf("ensureSocketDir for /: %v", err)
+	}
+	if err := ensureSocketDir("."); err != nil {
+		t.Fatalf("ensureSocketDir for .: %v", err)
+	}
+}
+
+func TestCheckAuthBearer(t *testing.T) {
+	token := "bearer-token"
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+	if !checkAuth(ctx, token) {
+		t.Fatal("expected bearer token to be accepted")
+	}
+	if checkAuth(context.Background(), token) {
+		t.Fatal("expected missing metadata to be rejected")
+	}
+}
+
+func TestInterceptorsNoTokenOrLimiter(t *testing.T) {
+	unary := unaryAuthInterceptor("")
+	if _, err := unary(context.Background(), nil, nil, func(ctx context.Context, req any) (any, error) {
+		return "ok", nil
+	}); err != nil {
+		t.Fatalf("expected no-token unary to pass: %v", err)
+	}
+
+	stream := streamAuthInterceptor("")
+	if err := stream(nil, &fakeServerStream{ctx: context.Background()}, nil, func(srv any, stream grpc.ServerStream) error {
+		return nil
+	}); err != nil {
+		t.Fatalf("expected no-token stream to pass: %v", err)
+	}
+
+	rateUnary := unaryRateInterceptor(nil, "global")
+	if _, err := rateUnary(context.Background(), nil, nil, func(ctx context.Context, req any) (any, error) {
+		return "ok", nil
+	}); err != nil {
+		t.Fatalf("expected nil limiter unary to pass: %v", err)
+	}
+
+	rateStream := streamRateInterceptor(nil, "global")
+	if err := rateStream(nil, &fakeServerStream{ctx: context.Background()}, nil, func(srv any, stream grpc.ServerStream) error {
+		return nil
+	}); err != nil {
+		t.Fatalf("expected nil limiter stream to pass: %v", err)
+	}
+}
+
+func TestGRPCServerLifecycle(t *testing.T) {
+	dir := t.TempDir()
+	cfg := GRPCConfig{
+		UDSPath:              filepath.Join(dir, "metrics.sock"),
+		SocketMode:           0600,
+		MaxRecvBytes:         1024,
+		MaxConcurrentStreams: 10,
+		KAEnforcementMinTime: time.Millisecond,
+		KATime:               time.Millisecond,
+		KATimeout:            time.Millisecond,
+		MaxConnIdle:          time.Millisecond,
+		MaxConnAge:           time.Millisecond,
+		MaxConnAgeGrace:      time.Millisecond,
+	}
+
+	srv, err := NewGRPCServer(cfg, &fakeIngestServer{}, nil)
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Serve()
+	}()
+
+	if conn, err := net.Dial("unix", cfg.UDSPath); err == nil {
+		_ = conn.Close()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("server did not stop")
+	}
+
+	if _, err := net.Dial("unix", cfg.UDSPath); err == nil {
+		t.Fatal("expected socket to be removed")
+	}
+}
+
+func TestGRPCServerReadyTogglesAcrossLifecycle(t *testing.T) {
+	dir := t.TempDir()
+	cfg := GRPCConfig{
+		UDSPath:              filepath.Join(dir, "metrics.sock"),
+		SocketMode:           0600,
+		MaxRecvBytes:         1024,
+		MaxConcurrentStreams: 10,
+		KAEnforcementMinTime: time.Millisecond,
+		KATime:               time.Millisecond,
+		KATimeout:            time.Millisecond,
+		MaxConnIdle:          time.Millisecond,
+		MaxConnAge:           time.Millisecond,
+		MaxConnAgeGrace:      time.Millisecond,
+	}
+
+	srv, err := NewGRPCServer(cfg, &fakeIngestServer{}, nil)
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+
+	if !srv.Ready() {
+		t.Fatal("expected server to be ready once constructed")
+	}
+
+	go func() { _ = srv.Serve() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+
+	if srv.Ready() {
+		t.Fatal("expected server to report not ready after shutdown")
+	}
+}
+
+func TestGRPCServerRegistersHealthService(t *testing.T) {
+	dir := t.TempDir()
+	cfg := GRPCConfig{
+		UDSPath:              filepath.Join(dir, "metrics.sock"),
+		SocketMode:           0600,
+		MaxRecvBytes:         1024,
+		MaxConcurrentStreams: 10,
+		KAEnforcementMinTime: time.Millisecond,
+		KATime:               time.Millisecond,
+		KATimeout:            time.Millisecond,
+		MaxConnIdle:          time.Millisecond,
+		MaxConnAge:           time.Millisecond,
+		MaxConnAgeGrace:      time.Millisecond,
+	}
+
+	srv, err := NewGRPCServer(cfg, &fakeIngestServer{}, nil)
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+
+	resp, err := srv.health.Check(context.Background(), &healthpb.HealthCheckRequest{Service: ingestServiceName})
+	if err != nil {
+		t.Fatalf("health check: %v", err)
+	}
+	if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("expected SERVING, got %v", resp.Status)
+	}
+}
+
+// TestGRPCServerAcceptsTrafficWithDefaultQuotaConfig guards against the
+// quota registry being built from GRPCConfig's zero-value defaults
+// (QuotaDefaultRPS/QuotaDefaultBurst both 0, as LoadGRPCConfigFromEnv
+// produces when GRPC_QUOTA_RPS/GRPC_QUOTA_BURST are unset): a registry
+// built from those defaults would hand every source a rate.Limiter with
+// Burst 0, which never allows a single message through.
+func TestGRPCServerAcceptsTrafficWithDefaultQuotaConfig(t *testing.T) {
+	dir := t.TempDir()
+	cfg := GRPCConfig{
+		UDSPath:              filepath.Join(dir, "metrics.sock"),
+		SocketMode:           0600,
+		MaxRecvBytes:         1024,
+		MaxConcurrentStreams: 10,
+		KAEnforcementMinTime: time.Millisecond,
+		KATime:               time.Millisecond,
+		KATimeout:            time.Millisecond,
+		MaxConnIdle:          time.Millisecond,
+		MaxConnAge:           time.Millisecond,
+		MaxConnAgeGrace:      time.Millisecond,
+	}
+
+	_, all := metrics.NewMetricsRegistry()
+	svc := ingest.NewService(all)
+
+	srv, err := NewGRPCServer(cfg, svc, nil)
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+	go func() { _ = srv.Serve() }()
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = srv.Shutdown(ctx)
+	}()
+
+	cc, err := grpc.NewClient("unix://"+cfg.UDSPath, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer cc.Close()
+
+	stream, err := pb.NewMetricsIngestorClient(cc).Push(context.Background())
+	if err != nil {
+		t.Fatalf("open push stream: %v", err)
+	}
+
+	if err := stream.Send(&pb.MetricsBatch{
+		SourceId: "default-quota-test",
+		DiskOps: []*pb.DiskOp{
+			{DiskId: "disk0", Op: pb.IoOpType_IO_OP_READ, Bytes: 1024, LatencySeconds: 0.01},
+		},
+	}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		t.Fatalf("expected batch to be accepted with default quota config, got: %v", err)
+	}
+	if resp.GetAcceptedBatches() != 1 {
+		t.Fatalf("expected 1 accepted batch, got %d", resp.GetAcceptedBatches())
+	}
+	if resp.GetRejectedSamples() != 0 {
+		t.Fatalf("expected no rejected samples, got %d", resp.GetRejectedSamples())
+	}
+}
@@ -14,7 +14,7 @@ func TestMetricsEndpointServesPrometheusOutput(t *testing.T) {
 
 	all.Disks.ReadOps.WithLabelValues("disk0").Inc()
 
-	mux := NewMux(reg)
+	mux := NewMux(reg, nil, nil, nil)
 	ts := httptest.NewServer(mux)
 	defer ts.Close()
 
@@ -43,10 +43,51 @@ func TestMetricsEndpointServesPrometheusOutput(t *testing.T) {
 	}
 }
 
+func TestMetricsEndpointCallsOnScrapeHook(t *testing.T) {
+	reg, _ := metrics.NewMetricsRegistry()
+
+	var calls int
+	mux := NewMux(reg, func() func() { calls++; return nil }, nil, nil)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("GET /metrics failed: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if calls != 1 {
+		t.Fatalf("expected onScrape to run once, ran %d times", calls)
+	}
+}
+
+func TestMetricsEndpointCallsOnScrapeBeforeGatherAndAfterHookAfterward(t *testing.T) {
+	reg, _ := metrics.NewMetricsRegistry()
+
+	var order []string
+	mux := NewMux(reg, func() func() {
+		order = append(order, "before")
+		return func() { order = append(order, "after") }
+	}, nil, nil)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("GET /metrics failed: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if len(order) != 2 || order[0] != "before" || order[1] != "after" {
+		t.Fatalf("expected onScrape before Gather and its returned hook after, got %v", order)
+	}
+}
+
 func TestHealthzEndpointReturnsOK(t *testing.T) {
 	reg, _ := metrics.NewMetricsRegistry()
 
-	mux := NewMux(reg)
+	mux := NewMux(reg, nil, nil, nil)
 	ts := httptest.NewServer(mux)
 	defer ts.Close()
 
@@ -74,3 +115,88 @@ func TestHealthzEndpointReturnsOK(t *testing.T) {
 		t.Fatalf(`expected body "ok", got %q`, body)
 	}
 }
+
+func TestReadyzReportsNotReadyThenReady(t *testing.T) {
+	reg, _ := metrics.NewMetricsRegistry()
+
+	ready := false
+	mux := NewMux(reg, nil, func() bool { return ready }, nil)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/readyz")
+	if err != nil {
+		t.Fatalf("GET /readyz failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 before ready, got %d", resp.StatusCode)
+	}
+
+	ready = true
+
+	resp, err = http.Get(ts.URL + "/readyz")
+	if err != nil {
+		t.Fatalf("GET /readyz failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 once ready, got %d", resp.StatusCode)
+	}
+}
+
+func TestReadyzDefaultsToReadyWithoutHook(t *testing.T) {
+	reg, _ := metrics.NewMetricsRegistry()
+
+	mux := NewMux(reg, nil, nil, nil)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/readyz")
+	if err != nil {
+		t.Fatalf("GET /readyz failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 with no ready hook, got %d", resp.StatusCode)
+	}
+}
+
+func TestBenchEndpointOmittedWithoutHandler(t *testing.T) {
+	reg, _ := metrics.NewMetricsRegistry()
+
+	mux := NewMux(reg, nil, nil, nil)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/bench")
+	if err != nil {
+		t.Fatalf("GET /bench failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 for /bench with no handler configured, got %d", resp.StatusCode)
+	}
+}
+
+func TestBenchEndpointServesProvidedHandler(t *testing.T) {
+	reg, _ := metrics.NewMetricsRegistry()
+
+	bench := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"aggregate":1}`))
+	})
+
+	mux := NewMux(reg, nil, nil, bench)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/bench")
+	if err != nil {
+		t.Fatalf("GET /bench failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 from /bench, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"context"
+	"crypto/x509"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func certIdentities(cert *x509.Certificate) []string {
+	ids := []string{cert.Subject.CommonName}
+	ids = append(ids, cert.DNSNames...)
+	for _, u := range cert.URIs {
+		ids = append(ids, u.String())
+	}
+	return ids
+}
+
+// primaryIdentity picks the identity to stash into the context for a cert
+// that passed identityAllowed: a SPIFFE (or other URI SAN) cert frequently
+// carries an empty CN, so prefer a URI SAN over an empty CN rather than
+// handing checkAuth's peer-identity path a blank string.
+func primaryIdentity(cert *x509.Certificate) string {
+	if cert.Subject.CommonName != "" {
+		return cert.Subject.CommonName
+	}
+	if len(cert.URIs) > 0 {
+		return cert.URIs[0].String()
+	}
+	return ""
+}
+
+func identityAllowed(cert *x509.Certificate, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+
+	for _, id := range certIdentities(cert) {
+		for _, a := range allowed {
+			if id == a {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type peerIdentityKey struct{}
+
+// withPeerIdentity stashes a verified client certificate identity (its CN,
+// or a URI SAN such as a SPIFFE ID when the CN is empty) into ctx so
+// checkAuth and RPC handlers downstream of the mTLS interceptor can read it
+// without re-inspecting the peer's TLS state.
+func withPeerIdentity(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, peerIdentityKey{}, id)
+}
+
+// peerIdentityFromContext returns the verified client certificate identity
+// stashed by the mTLS interceptor, if any.
+func peerIdentityFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(peerIdentityKey{}).(string)
+	return id, ok
+}
+
+// checkMTLSIdentity inspects ctx for a presented client certificate. If one
+// is present, it is enforced against allowed (a CN/SAN allow-list) and, once
+// accepted, its primaryIdentity is returned so it can be stashed into the
+// context. Connections with no client certificate (UDS, or TCP without
+// RequireClientCert) return ("", nil) so checkAuth's bearer token remains
+// the deciding factor for them.
+func checkMTLSIdentity(ctx context.Context, allowed []string) (string, error) {
+	p, present := peer.FromContext(ctx)
+	if !present {
+		return "", nil
+	}
+
+	tlsInfo, isTLS := p.AuthInfo.(credentials.TLSInfo)
+	if !isTLS || len(tlsInfo.State.PeerCertificates) == 0 {
+		return "", nil
+	}
+
+	cert := tlsInfo.State.PeerCertificates[0]
+	if !identityAllowed(cert, allowed) {
+		return "", status.Error(codes.PermissionDenied, "client certificate identity not allowed")
+	}
+	return primaryIdentity(cert), nil
+}
+
+func unaryMTLSIdentityInterceptor(allowed []string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		id, err := checkMTLSIdentity(ctx, allowed)
+		if err != nil {
+			return nil, err
+		}
+		if id != "" {
+			ctx = withPeerIdentity(ctx, id)
+		}
+		return handler(ctx, req)
+	}
+}
+
+func streamMTLSIdentityInterceptor(allowed []string) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		id, err := checkMTLSIdentity(ss.Context(), allowed)
+		if err != nil {
+			return err
+		}
+		if id != "" {
+			ss = &identityServerStream{ServerStream: ss, ctx: withPeerIdentity(ss.Context(), id)}
+		}
+		return handler(srv, ss)
+	}
+}
+
+// identityServerStream overrides Context() so a verified client identity set
+// by streamMTLSIdentityInterceptor is visible to interceptors and handlers
+// further down the chain (mirrors rateLimitedServerStream's wrap pattern).
+type identityServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *identityServerStream) Context() context.Context {
+	return s.ctx
+}
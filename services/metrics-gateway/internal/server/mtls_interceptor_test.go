@@ -0,0 +1,108 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/url"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func tlsPeerContext(cn string) context.Context {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: cn}}
+	return peerContextWithCert(cert)
+}
+
+// spiffePeerContext mimics a SPIFFE-issued cert: identity lives entirely in
+// a spiffe:// URI SAN, with no CommonName set.
+func spiffePeerContext(spiffeID string) context.Context {
+	u, err := url.Parse(spiffeID)
+	if err != nil {
+		panic(err)
+	}
+	cert := &x509.Certificate{URIs: []*url.URL{u}}
+	return peerContextWithCert(cert)
+}
+
+func peerContextWithCert(cert *x509.Certificate) context.Context {
+	p := &peer.Peer{
+		AuthInfo: credentials.TLSInfo{
+			State: tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}},
+		},
+	}
+	return peer.NewContext(context.Background(), p)
+}
+
+func TestCheckMTLSIdentityPassesWithoutPeer(t *testing.T) {
+	id, err := checkMTLSIdentity(context.Background(), []string{"allowed"})
+	if err != nil {
+		t.Fatalf("expected no peer to pass through, got %v", err)
+	}
+	if id != "" {
+		t.Fatalf("expected no identity without a peer, got %q", id)
+	}
+}
+
+func TestCheckMTLSIdentityAllowsMatchingCN(t *testing.T) {
+	ctx := tlsPeerContext("producer-a")
+	id, err := checkMTLSIdentity(ctx, []string{"producer-a", "producer-b"})
+	if err != nil {
+		t.Fatalf("expected matching CN to pass, got %v", err)
+	}
+	if id != "producer-a" {
+		t.Fatalf("expected identity %q, got %q", "producer-a", id)
+	}
+}
+
+func TestCheckMTLSIdentityRejectsMismatchedCN(t *testing.T) {
+	ctx := tlsPeerContext("producer-z")
+	_, err := checkMTLSIdentity(ctx, []string{"producer-a", "producer-b"})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected PermissionDenied, got %v", err)
+	}
+}
+
+func TestCheckMTLSIdentityEmptyAllowlistPassesAnyCert(t *testing.T) {
+	ctx := tlsPeerContext("anyone")
+	id, err := checkMTLSIdentity(ctx, nil)
+	if err != nil {
+		t.Fatalf("expected empty allowlist to pass any cert, got %v", err)
+	}
+	if id != "anyone" {
+		t.Fatalf("expected identity %q, got %q", "anyone", id)
+	}
+}
+
+func TestCheckMTLSIdentityAllowsMatchingSPIFFEURI(t *testing.T) {
+	ctx := spiffePeerContext("spiffe://cluster.local/producer-a")
+	id, err := checkMTLSIdentity(ctx, []string{"spiffe://cluster.local/producer-a"})
+	if err != nil {
+		t.Fatalf("expected matching SPIFFE URI SAN to pass, got %v", err)
+	}
+	if id != "spiffe://cluster.local/producer-a" {
+		t.Fatalf("expected identity %q, got %q", "spiffe://cluster.local/producer-a", id)
+	}
+}
+
+func TestUnaryMTLSIdentityInterceptorStashesIdentityInContext(t *testing.T) {
+	ctx := tlsPeerContext("producer-a")
+	interceptor := unaryMTLSIdentityInterceptor([]string{"producer-a"})
+
+	var gotID string
+	_, err := interceptor(ctx, nil, nil, func(ctx context.Context, req any) (any, error) {
+		gotID, _ = peerIdentityFromContext(ctx)
+		return "ok", nil
+	})
+	if err != nil {
+		t.Fatalf("expected handler to run, got %v", err)
+	}
+	if gotID != "producer-a" {
+		t.Fatalf("expected handler context to carry identity %q, got %q", "producer-a", gotID)
+	}
+}
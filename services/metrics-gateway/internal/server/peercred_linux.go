@@ -0,0 +1,34 @@
+//go:build linux
+
+package server
+
+import (
+	"fmt"
+	"net"
+
+	"golang.org/x/sys/unix"
+)
+
+// peerCredFromConn reads the connecting process's uid/gid/pid off conn via
+// SO_PEERCRED, the Linux mechanism for retrieving the credentials a kernel
+// attached to a Unix domain socket at connect time (so they cannot be
+// spoofed by the peer itself).
+func peerCredFromConn(conn *net.UnixConn) (PeerCredInfo, error) {
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return PeerCredInfo{}, fmt.Errorf("syscall conn: %w", err)
+	}
+
+	var ucred *unix.Ucred
+	var sockErr error
+	if err := raw.Control(func(fd uintptr) {
+		ucred, sockErr = unix.GetsockoptUcred(int(fd), unix.SOL_SOCKET, unix.SO_PEERCRED)
+	}); err != nil {
+		return PeerCredInfo{}, fmt.Errorf("control: %w", err)
+	}
+	if sockErr != nil {
+		return PeerCredInfo{}, fmt.Errorf("getsockopt SO_PEERCRED: %w", sockErr)
+	}
+
+	return PeerCredInfo{UID: ucred.Uid, GID: ucred.Gid, PID: ucred.Pid}, nil
+}
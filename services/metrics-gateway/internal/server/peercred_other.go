@@ -0,0 +1,17 @@
+//go:build !linux
+
+package server
+
+import (
+	"fmt"
+	"net"
+)
+
+// peerCredFromConn is unimplemented outside Linux. BSD/macOS expose the
+// equivalent credential via LOCAL_PEERCRED, but this server only ships a
+// Linux build today; the caller treats this error as "peer-cred auth
+// unavailable" and falls back to the bearer token when no allow-list is
+// configured.
+func peerCredFromConn(conn *net.UnixConn) (PeerCredInfo, error) {
+	return PeerCredInfo{}, fmt.Errorf("peer credentials are not supported on this platform")
+}
@@ -0,0 +1,28 @@
+package quota
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// overridesFile is the on-disk shape of a per-source overrides file: a flat
+// map of source_id to Limits, in either YAML or JSON (YAML is a superset of
+// JSON, so one decoder handles both).
+type overridesFile map[string]Limits
+
+// LoadOverrides reads and parses a per-source overrides file from path.
+func LoadOverrides(path string) (map[string]Limits, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("quota: read overrides file %s: %w", path, err)
+	}
+
+	var f overridesFile
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("quota: parse overrides file %s: %w", path, err)
+	}
+
+	return map[string]Limits(f), nil
+}
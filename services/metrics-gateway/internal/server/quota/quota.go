@@ -0,0 +1,202 @@
+// Package quota implements keyed token-bucket rate limiting for the ingest
+// gRPC server, so one noisy caller sharing a connection can't starve the
+// others the way a single global limiter would. The default key is a
+// batch's source_id (see NewRegistry); NewKeyedRegistry generalizes the
+// same bounded, LRU- and idle-TTL-evicted registry to other keys, such as
+// the connection identity used to shard the server's top-level rate limit.
+package quota
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+)
+
+// Limits bounds one source_id's allowed request rate and, optionally, its
+// inbound byte rate.
+type Limits struct {
+	RPS            float64 `yaml:"rps" json:"rps"`
+	Burst          int     `yaml:"burst" json:"burst"`
+	BytesPerSecond float64 `yaml:"bytes_per_second" json:"bytes_per_second"`
+}
+
+type limiterEntry struct {
+	key      string
+	rps      *rate.Limiter
+	bytes    *rate.Limiter
+	lastUsed time.Time
+}
+
+// Registry lazily allocates a token bucket per key (a source_id, or any
+// other caller-chosen identity — see NewKeyedRegistry), bounded to
+// maxEntries via LRU eviction and, optionally, idleTTL since an entry was
+// last used, with optional per-key overrides that can be swapped at
+// runtime (e.g. on SIGHUP).
+type Registry struct {
+	mu         sync.Mutex
+	maxEntries int
+	idleTTL    time.Duration
+	def        Limits
+	overrides  map[string]Limits
+	entries    map[string]*list.Element
+	order      *list.List
+
+	evictions prometheus.Counter
+	limited   *prometheus.CounterVec
+}
+
+// NewRegistry constructs a Registry keyed by source_id, with no idle-TTL
+// eviction (entries live until they fall out of the maxEntries LRU). reg
+// may be nil to skip metrics registration (e.g. in tests).
+func NewRegistry(maxEntries int, def Limits, reg prometheus.Registerer) *Registry {
+	return NewKeyedRegistry(maxEntries, 0, def, "ingest", "source_id", reg)
+}
+
+// NewKeyedRegistry is the general form of NewRegistry: it additionally
+// accepts an idleTTL (0 disables idle eviction) and the metric name prefix
+// and key label to use, so a second Registry keyed by something other than
+// source_id (e.g. a connection identity) can coexist in the same
+// prometheus.Registerer without a metric name collision.
+func NewKeyedRegistry(maxEntries int, idleTTL time.Duration, def Limits, metricPrefix, keyLabel string, reg prometheus.Registerer) *Registry {
+	r := &Registry{
+		maxEntries: maxEntries,
+		idleTTL:    idleTTL,
+		def:        def,
+		overrides:  map[string]Limits{},
+		entries:    map[string]*list.Element{},
+		order:      list.New(),
+	}
+
+	if reg != nil {
+		r.evictions = prometheus.NewCounter(prometheus.CounterOpts{
+			Name: metricPrefix + "_lru_evictions_total",
+			Help: "Total number of per-" + keyLabel + " rate limiters evicted from the bounded LRU cache.",
+		})
+		r.limited = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: metricPrefix + "_rate_limited_total",
+			Help: "Total number of requests rejected by the per-" + keyLabel + " rate limiter.",
+		}, []string{keyLabel})
+
+		reg.MustRegister(r.evictions, r.limited)
+	}
+
+	return r
+}
+
+// SetOverrides atomically swaps the per-source override table. Existing
+// limiters already allocated for a source keep running with their old
+// limits; only sources seen for the first time afterwards pick up the
+// new values. This matches the hot-reload semantics operators expect from
+// a config file watched on SIGHUP.
+func (r *Registry) SetOverrides(overrides map[string]Limits) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.overrides = overrides
+}
+
+func (r *Registry) limitsFor(sourceID string) Limits {
+	if l, ok := r.overrides[sourceID]; ok {
+		return l
+	}
+	return r.def
+}
+
+func (r *Registry) get(sourceID string) *limiterEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.evictIdleLocked()
+
+	if el, ok := r.entries[sourceID]; ok {
+		e := el.Value.(*limiterEntry)
+		e.lastUsed = time.Now()
+		r.order.MoveToFront(el)
+		return e
+	}
+
+	lim := r.limitsFor(sourceID)
+	e := &limiterEntry{
+		key:      sourceID,
+		rps:      rate.NewLimiter(rate.Limit(lim.RPS), lim.Burst),
+		lastUsed: time.Now(),
+	}
+	if lim.BytesPerSecond > 0 {
+		burstBytes := int(lim.BytesPerSecond)
+		if burstBytes < 1 {
+			burstBytes = 1
+		}
+		e.bytes = rate.NewLimiter(rate.Limit(lim.BytesPerSecond), burstBytes)
+	}
+
+	el := r.order.PushFront(e)
+	r.entries[sourceID] = el
+
+	if r.maxEntries > 0 && r.order.Len() > r.maxEntries {
+		back := r.order.Back()
+		if back != nil {
+			evicted := back.Value.(*limiterEntry)
+			delete(r.entries, evicted.key)
+			r.order.Remove(back)
+			if r.evictions != nil {
+				r.evictions.Inc()
+			}
+		}
+	}
+
+	return e
+}
+
+// evictIdleLocked drops entries that haven't been used within idleTTL. It
+// walks from the back of the LRU list (oldest-used first) and stops at the
+// first entry still within the TTL, since every entry in front of it is
+// more recently used. Callers must hold r.mu.
+func (r *Registry) evictIdleLocked() {
+	if r.idleTTL <= 0 {
+		return
+	}
+
+	cutoff := time.Now().Add(-r.idleTTL)
+	for {
+		back := r.order.Back()
+		if back == nil {
+			return
+		}
+		e := back.Value.(*limiterEntry)
+		if e.lastUsed.After(cutoff) {
+			return
+		}
+		delete(r.entries, e.key)
+		r.order.Remove(back)
+		if r.evictions != nil {
+			r.evictions.Inc()
+		}
+	}
+}
+
+// Allow reports whether a request of nBytes from sourceID should be admitted,
+// consuming one RPS token and, if a byte cap is configured, nBytes worth of
+// the byte-rate bucket.
+func (r *Registry) Allow(sourceID string, nBytes int) bool {
+	e := r.get(sourceID)
+
+	if !e.rps.Allow() {
+		r.recordLimited(sourceID)
+		return false
+	}
+
+	if e.bytes != nil && !e.bytes.AllowN(time.Now(), nBytes) {
+		r.recordLimited(sourceID)
+		return false
+	}
+
+	return true
+}
+
+func (r *Registry) recordLimited(sourceID string) {
+	if r.limited != nil {
+		r.limited.WithLabelValues(sourceID).Inc()
+	}
+}
@@ -0,0 +1,121 @@
+package quota
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRegistryAllowsWithinBurstThenRejects(t *testing.T) {
+	r := NewRegistry(10, Limits{RPS: 0, Burst: 2}, nil)
+
+	if !r.Allow("src-a", 0) {
+		t.Fatal("expected first request to be allowed")
+	}
+	if !r.Allow("src-a", 0) {
+		t.Fatal("expected second request within burst to be allowed")
+	}
+	if r.Allow("src-a", 0) {
+		t.Fatal("expected third request to be rate limited")
+	}
+}
+
+func TestRegistryPerSourceIsolation(t *testing.T) {
+	r := NewRegistry(10, Limits{RPS: 0, Burst: 1}, nil)
+
+	if !r.Allow("src-a", 0) {
+		t.Fatal("expected src-a first request to be allowed")
+	}
+	if !r.Allow("src-b", 0) {
+		t.Fatal("expected src-b to have its own independent bucket")
+	}
+}
+
+func TestRegistryEvictsLeastRecentlyUsed(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	r := NewRegistry(2, Limits{RPS: 100, Burst: 100}, reg)
+
+	r.Allow("a", 0)
+	r.Allow("b", 0)
+	r.Allow("c", 0) // should evict "a"
+
+	if testutil.ToFloat64(r.evictions) != 1 {
+		t.Fatalf("expected one eviction, got %f", testutil.ToFloat64(r.evictions))
+	}
+	if _, ok := r.entries["a"]; ok {
+		t.Fatal("expected least-recently-used entry to be evicted")
+	}
+}
+
+func TestRegistryOverridesApplyToNewSources(t *testing.T) {
+	r := NewRegistry(10, Limits{RPS: 0, Burst: 1}, nil)
+	r.SetOverrides(map[string]Limits{"vip": {RPS: 0, Burst: 5}})
+
+	for i := 0; i < 5; i++ {
+		if !r.Allow("vip", 0) {
+			t.Fatalf("expected vip request %d to be allowed under its override burst", i)
+		}
+	}
+	if r.Allow("vip", 0) {
+		t.Fatal("expected vip to be rate limited after exhausting its override burst")
+	}
+}
+
+func TestRegistryBytesPerSecondCap(t *testing.T) {
+	r := NewRegistry(10, Limits{RPS: 1000, Burst: 1000, BytesPerSecond: 100}, nil)
+
+	if !r.Allow("src", 50) {
+		t.Fatal("expected request under the byte cap to be allowed")
+	}
+	if r.Allow("src", 1000) {
+		t.Fatal("expected request exceeding the byte cap to be rejected")
+	}
+}
+
+func TestKeyedRegistryEvictsIdleEntries(t *testing.T) {
+	r := NewKeyedRegistry(10, time.Millisecond, Limits{RPS: 100, Burst: 100}, "ingest_conn", "identity", nil)
+
+	r.Allow("peer-a", 0)
+	time.Sleep(5 * time.Millisecond)
+
+	// Touching a different key runs eviction as a side effect of get().
+	r.Allow("peer-b", 0)
+
+	if _, ok := r.entries["peer-a"]; ok {
+		t.Fatal("expected idle entry to be evicted")
+	}
+}
+
+func TestKeyedRegistryUsesDistinctMetricNames(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	connLimiter := NewKeyedRegistry(10, 0, Limits{RPS: 100, Burst: 100}, "ingest_conn", "identity", reg)
+	sourceLimiter := NewRegistry(10, Limits{RPS: 100, Burst: 100}, reg)
+
+	connLimiter.Allow("peer-a", 0)
+	sourceLimiter.Allow("src-a", 0)
+}
+
+func TestLoadOverridesParsesYAML(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "overrides.yaml")
+	content := "vip:\n  rps: 50\n  burst: 100\nnoisy:\n  rps: 1\n  burst: 1\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write overrides file: %v", err)
+	}
+
+	overrides, err := LoadOverrides(path)
+	if err != nil {
+		t.Fatalf("LoadOverrides: %v", err)
+	}
+	if overrides["vip"].RPS != 50 || overrides["vip"].Burst != 100 {
+		t.Fatalf("unexpected vip overrides: %+v", overrides["vip"])
+	}
+	if overrides["noisy"].RPS != 1 {
+		t.Fatalf("unexpected noisy overrides: %+v", overrides["noisy"])
+	}
+}
+
@@ -0,0 +1,39 @@
+package quota
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// WatchSIGHUP reloads the overrides file at path and calls apply with the
+// result every time the process receives SIGHUP, until ctx is canceled.
+// Reload errors are logged and leave the previous overrides in place.
+func WatchSIGHUP(ctx context.Context, path string, apply func(map[string]Limits)) {
+	if path == "" {
+		return
+	}
+
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+
+	go func() {
+		defer signal.Stop(sighup)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sighup:
+				overrides, err := LoadOverrides(path)
+				if err != nil {
+					log.Printf("quota: reload %s failed, keeping previous overrides: %v", path, err)
+					continue
+				}
+				apply(overrides)
+			}
+		}
+	}()
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"time"
+
+	"metrics-gateway/internal/server/quota"
+
+	pb "metrics-gateway/internal/pb/metrics/v1"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// streamQuotaInterceptor enforces a per-source_id token bucket on every
+// message of an ingest stream, peeking MetricsBatch.SourceId off each
+// message as it's received. Requests from a source without one are bucketed
+// under a per-connection fallback key so omitting source_id can't be used to
+// dodge the quota.
+func streamQuotaInterceptor(registry *quota.Registry) grpc.StreamServerInterceptor {
+	if registry == nil {
+		return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			return handler(srv, ss)
+		}
+	}
+
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		wrapped := &quotaServerStream{
+			ServerStream: ss,
+			registry:     registry,
+			fallbackKey:  fallbackQuotaKey(ss),
+		}
+		return handler(srv, wrapped)
+	}
+}
+
+func fallbackQuotaKey(ss grpc.ServerStream) string {
+	p, ok := peer.FromContext(ss.Context())
+	if !ok || p.Addr == nil {
+		return "unknown"
+	}
+	return p.Addr.String()
+}
+
+type quotaServerStream struct {
+	grpc.ServerStream
+	registry    *quota.Registry
+	fallbackKey string
+}
+
+func (s *quotaServerStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	sourceID := s.fallbackKey
+	size := 0
+	if batch, ok := m.(*pb.MetricsBatch); ok {
+		if batch.GetSourceId() != "" {
+			sourceID = batch.GetSourceId()
+		}
+		size = proto.Size(batch)
+	}
+
+	if !s.registry.Allow(sourceID, size) {
+		return quotaExhaustedError(sourceID)
+	}
+	return nil
+}
+
+func quotaExhaustedError(sourceID string) error {
+	st := status.New(codes.ResourceExhausted, "rate limited: source "+sourceID)
+	withDetail, err := st.WithDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(time.Second),
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return withDetail.Err()
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"metrics-gateway/internal/server/quota"
+
+	pb "metrics-gateway/internal/pb/metrics/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeMsgStream struct {
+	fakeServerStream
+	msgs []*pb.MetricsBatch
+	idx  int
+}
+
+func (f *fakeMsgStream) RecvMsg(m any) error {
+	batch := m.(*pb.MetricsBatch)
+	*batch = *f.msgs[f.idx]
+	f.idx++
+	return nil
+}
+
+func TestStreamQuotaInterceptorRejectsAfterBurst(t *testing.T) {
+	registry := quota.NewRegistry(10, quota.Limits{RPS: 0, Burst: 1}, nil)
+
+	qs := &quotaServerStream{
+		ServerStream: &fakeMsgStream{
+			fakeServerStream: fakeServerStream{ctx: context.Background()},
+			msgs: []*pb.MetricsBatch{
+				{SourceId: "src"},
+				{SourceId: "src"},
+			},
+		},
+		registry:    registry,
+		fallbackKey: "fallback",
+	}
+
+	if err := qs.RecvMsg(&pb.MetricsBatch{}); err != nil {
+		t.Fatalf("expected first message to be allowed: %v", err)
+	}
+
+	err := qs.RecvMsg(&pb.MetricsBatch{})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected resource exhausted, got %v", err)
+	}
+}
+
+func TestStreamQuotaInterceptorPassthroughWhenNil(t *testing.T) {
+	interceptor := streamQuotaInterceptor(nil)
+	ss := &fakeServerStream{ctx: context.Background()}
+
+	called := false
+	err := interceptor(nil, ss, nil, func(srv any, stream grpc.ServerStream) error {
+		called = true
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !called {
+		t.Fatal("expected handler to be invoked")
+	}
+}
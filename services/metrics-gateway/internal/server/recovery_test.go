@@ -0,0 +1,49 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryRecoveryInterceptorConvertsPanicToInternal(t *testing.T) {
+	interceptor := unaryRecoveryInterceptor()
+
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/metrics.v1.MetricsIngestor/Push"},
+		func(ctx context.Context, req any) (any, error) {
+			panic("boom")
+		})
+
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("expected codes.Internal, got %v", err)
+	}
+}
+
+func TestUnaryRecoveryInterceptorPassesThroughNormally(t *testing.T) {
+	interceptor := unaryRecoveryInterceptor()
+
+	resp, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/metrics.v1.MetricsIngestor/Push"},
+		func(ctx context.Context, req any) (any, error) {
+			return "ok", nil
+		})
+
+	if err != nil || resp != "ok" {
+		t.Fatalf("expected normal response to pass through, got resp=%v err=%v", resp, err)
+	}
+}
+
+func TestStreamRecoveryInterceptorConvertsPanicToInternal(t *testing.T) {
+	interceptor := streamRecoveryInterceptor()
+
+	err := interceptor(nil, &fakeServerStream{ctx: context.Background()}, &grpc.StreamServerInfo{FullMethod: "/metrics.v1.MetricsIngestor/PushStream"},
+		func(srv any, ss grpc.ServerStream) error {
+			panic("stream boom")
+		})
+
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("expected codes.Internal, got %v", err)
+	}
+}
@@ -5,23 +5,64 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"metrics-gateway/internal/metrics/influx"
 )
 
-func NewMux(reg *prometheus.Registry) *http.ServeMux {
+// NewMux builds the metrics-gateway's HTTP mux. onScrape, if non-nil, is
+// called immediately before every /metrics Gather, and the func it returns
+// (if any) is called once that scrape completes — the WAL checkpoint-advance
+// hook uses the pre-Gather call to snapshot the WAL position so it only ever
+// checkpoints batches it can prove were reflected in the scrape that just
+// happened, not ones appended during the scrape itself. ready, if non-nil,
+// backs /readyz: NOT_READY until it returns true (e.g. before the ingest
+// socket is listening, and again during shutdown), distinct from /healthz's
+// plain liveness check. bench, if non-nil, is mounted at /bench (see
+// internal/bench.NewHTTPHandler); omitting it drops the endpoint entirely
+// rather than mounting a stub.
+func NewMux(reg *prometheus.Registry, onScrape func() func(), ready func() bool, bench http.Handler) *http.ServeMux {
 	mux := http.NewServeMux()
 
-	mux.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+	metricsHandler := promhttp.HandlerFor(reg, promhttp.HandlerOpts{})
+	mux.Handle("/metrics", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var afterScrape func()
+		if onScrape != nil {
+			afterScrape = onScrape()
+		}
+		metricsHandler.ServeHTTP(w, r)
+		if afterScrape != nil {
+			afterScrape()
+		}
+	}))
+
+	// /telegraf mirrors /metrics in InfluxDB line protocol, for Telegraf's
+	// inputs.http plugin to scrape without a Prometheus intermediary.
+	mux.Handle("/telegraf", influx.NewHTTPHandler(reg))
 
 	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte("ok\n"))
 	})
 
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, _ *http.Request) {
+		if ready != nil && !ready() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("not ready\n"))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ready\n"))
+	})
+
+	if bench != nil {
+		mux.Handle("/bench", bench)
+	}
+
 	return mux
 }
 
-func NewHTTPServer(addr string, reg *prometheus.Registry) *http.Server {
-	mux := NewMux(reg)
+func NewHTTPServer(addr string, reg *prometheus.Registry, onScrape func() func(), ready func() bool, bench http.Handler) *http.Server {
+	mux := NewMux(reg, onScrape, ready, bench)
 
 	return &http.Server{
 		Addr:    addr,
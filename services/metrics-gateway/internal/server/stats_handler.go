@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+)
+
+// redStatsHandler implements stats.Handler, recording RED (rate, errors,
+// duration) metrics plus request/response byte histograms per method into
+// the server's Prometheus registry. It is wired in via grpc.StatsHandler
+// so every RPC is observed regardless of which interceptors run.
+type redStatsHandler struct {
+	requestsTotal  *prometheus.CounterVec
+	inFlight       *prometheus.GaugeVec
+	reqBytes       *prometheus.HistogramVec
+	respBytes      *prometheus.HistogramVec
+	latencySeconds *prometheus.HistogramVec
+}
+
+func newRedStatsHandler(reg prometheus.Registerer) *redStatsHandler {
+	h := &redStatsHandler{
+		requestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ingest_rpc_requests_total",
+			Help: "Total number of gRPC requests, by method and status code.",
+		}, []string{"method", "code"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ingest_rpc_in_flight",
+			Help: "Number of gRPC requests currently being served, by method.",
+		}, []string{"method"}),
+		reqBytes: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "ingest_rpc_request_bytes",
+			Help:    "Size of gRPC request payloads, by method.",
+			Buckets: prometheus.ExponentialBuckets(64, 4, 8),
+		}, []string{"method"}),
+		respBytes: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "ingest_rpc_response_bytes",
+			Help:    "Size of gRPC response payloads, by method.",
+			Buckets: prometheus.ExponentialBuckets(64, 4, 8),
+		}, []string{"method"}),
+		latencySeconds: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "ingest_rpc_latency_seconds",
+			Help:    "End-to-end gRPC request latency, by method and status code.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "code"}),
+	}
+
+	if reg != nil {
+		reg.MustRegister(h.requestsTotal, h.inFlight, h.reqBytes, h.respBytes, h.latencySeconds)
+	}
+	return h
+}
+
+type statsRPCKey struct{}
+
+type rpcState struct {
+	method string
+}
+
+func (h *redStatsHandler) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	return context.WithValue(ctx, statsRPCKey{}, &rpcState{method: info.FullMethodName})
+}
+
+func (h *redStatsHandler) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	st, _ := ctx.Value(statsRPCKey{}).(*rpcState)
+	if st == nil {
+		return
+	}
+
+	switch v := s.(type) {
+	case *stats.Begin:
+		h.inFlight.WithLabelValues(st.method).Inc()
+	case *stats.InPayload:
+		h.reqBytes.WithLabelValues(st.method).Observe(float64(v.Length))
+	case *stats.OutPayload:
+		h.respBytes.WithLabelValues(st.method).Observe(float64(v.Length))
+	case *stats.End:
+		h.inFlight.WithLabelValues(st.method).Dec()
+		code := status.Code(v.Error).String()
+		h.requestsTotal.WithLabelValues(st.method, code).Inc()
+		h.latencySeconds.WithLabelValues(st.method, code).Observe(v.EndTime.Sub(v.BeginTime).Seconds())
+	}
+}
+
+func (h *redStatsHandler) TagConn(ctx context.Context, info *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (h *redStatsHandler) HandleConn(context.Context, stats.ConnStats) {}
@@ -0,0 +1,42 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc/stats"
+)
+
+func TestRedStatsHandlerRecordsRequestAndLatency(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	h := newRedStatsHandler(reg)
+
+	ctx := h.TagRPC(context.Background(), &stats.RPCTagInfo{FullMethodName: "/metrics.v1.MetricsIngestor/Push"})
+
+	begin := time.Now()
+	h.HandleRPC(ctx, &stats.Begin{BeginTime: begin})
+	h.HandleRPC(ctx, &stats.InPayload{Length: 128})
+	h.HandleRPC(ctx, &stats.OutPayload{Length: 16})
+	h.HandleRPC(ctx, &stats.End{BeginTime: begin, EndTime: begin.Add(10 * time.Millisecond)})
+
+	if count := testutil.CollectAndCount(h.requestsTotal); count != 1 {
+		t.Fatalf("expected one requestsTotal series, got %d", count)
+	}
+	if got := testutil.ToFloat64(h.requestsTotal.WithLabelValues("/metrics.v1.MetricsIngestor/Push", "OK")); got != 1 {
+		t.Fatalf("expected one OK request recorded, got %f", got)
+	}
+	if got := testutil.ToFloat64(h.inFlight.WithLabelValues("/metrics.v1.MetricsIngestor/Push")); got != 0 {
+		t.Fatalf("expected in-flight gauge back to 0 after End, got %f", got)
+	}
+}
+
+func TestRedStatsHandlerIgnoresUntaggedContext(t *testing.T) {
+	h := newRedStatsHandler(nil)
+
+	// No TagRPC call, so the context carries no rpcState; HandleRPC must
+	// not panic on the missing state.
+	h.HandleRPC(context.Background(), &stats.Begin{BeginTime: time.Now()})
+}
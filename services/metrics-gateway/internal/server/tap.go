@@ -0,0 +1,40 @@
+package server
+
+import (
+	"context"
+	"strconv"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/tap"
+)
+
+// AdmissionFunc is a caller-supplied admission policy invoked by the
+// server's tap.ServerInHandle, before an inbound request's payload is read
+// off the wire. It mirrors tap.ServerInHandle's own signature so it can be
+// set directly on GRPCConfig without an adapter, letting operators apply a
+// second-tier quota, shed load, or reject by method/identity ahead of the
+// auth and rate-limit interceptors (which only run once the payload has
+// already been decoded).
+type AdmissionFunc func(ctx context.Context, info *tap.Info) (context.Context, error)
+
+// newTapHandle builds the tap.ServerInHandle wired into grpc.InTapHandle.
+// It rejects frames over maxRecvBytes using the advertised
+// grpc-message-length header before the payload is decoded, then defers to
+// admit, if configured, for any further policy.
+func newTapHandle(maxRecvBytes int, admit AdmissionFunc) tap.ServerInHandle {
+	return func(ctx context.Context, info *tap.Info) (context.Context, error) {
+		if maxRecvBytes > 0 {
+			if vals := info.Header.Get("grpc-message-length"); len(vals) > 0 {
+				if n, err := strconv.Atoi(vals[0]); err == nil && n > maxRecvBytes {
+					return ctx, status.Errorf(codes.ResourceExhausted, "frame of %d bytes exceeds max %d for %s", n, maxRecvBytes, info.FullMethodName)
+				}
+			}
+		}
+
+		if admit != nil {
+			return admit(ctx, info)
+		}
+		return ctx, nil
+	}
+}
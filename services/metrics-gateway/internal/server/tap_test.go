@@ -0,0 +1,55 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/tap"
+)
+
+func TestNewTapHandleRejectsOversizedFrame(t *testing.T) {
+	handle := newTapHandle(1024, nil)
+
+	info := &tap.Info{
+		FullMethodName: "/metrics.v1.MetricsIngestor/Push",
+		Header:         metadata.Pairs("grpc-message-length", "4096"),
+	}
+
+	_, err := handle(context.Background(), info)
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected ResourceExhausted, got %v", err)
+	}
+}
+
+func TestNewTapHandlePassesSmallFrame(t *testing.T) {
+	handle := newTapHandle(1024, nil)
+
+	info := &tap.Info{
+		FullMethodName: "/metrics.v1.MetricsIngestor/Push",
+		Header:         metadata.Pairs("grpc-message-length", "64"),
+	}
+
+	if _, err := handle(context.Background(), info); err != nil {
+		t.Fatalf("expected small frame to pass, got %v", err)
+	}
+}
+
+func TestNewTapHandleDelegatesToAdmissionFunc(t *testing.T) {
+	var called bool
+	admit := func(ctx context.Context, info *tap.Info) (context.Context, error) {
+		called = true
+		return ctx, status.Error(codes.ResourceExhausted, "shedding load")
+	}
+
+	handle := newTapHandle(0, admit)
+	_, err := handle(context.Background(), &tap.Info{FullMethodName: "/metrics.v1.MetricsIngestor/Push"})
+	if !called {
+		t.Fatal("expected admission func to run")
+	}
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected admission func's error, got %v", err)
+	}
+}
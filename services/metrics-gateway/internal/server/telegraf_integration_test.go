@@ -0,0 +1,70 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"metrics-gateway/internal/ingest"
+	"metrics-gateway/internal/metrics"
+	pb "metrics-gateway/internal/pb/metrics/v1"
+)
+
+// TestTelegrafEndpointCoalescesLabelSets drives a batch through the same
+// ingest.Service.Apply path TestPushAggregatesCounters exercises, then
+// scrapes /telegraf and asserts the disk_id=disk0 line carries every field
+// that shares that label set as one coalesced line. The timestamp at the
+// end of the line is excluded from the comparison since it's wall-clock at
+// scrape time, not reproducible across test runs.
+func TestTelegrafEndpointCoalescesLabelSets(t *testing.T) {
+	reg, all := metrics.NewMetricsRegistry()
+	svc := ingest.NewService(all)
+
+	batch := &pb.MetricsBatch{
+		SourceId: "test",
+		DiskOps: []*pb.DiskOp{
+			{DiskId: "disk0", Op: pb.IoOpType_IO_OP_READ, Bytes: 4096, LatencySeconds: 0.002},
+		},
+	}
+	if err := svc.Apply(batch); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+
+	mux := NewMux(reg, nil, nil, nil)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/telegraf")
+	if err != nil {
+		t.Fatalf("GET /telegraf failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 from /telegraf, got %d", resp.StatusCode)
+	}
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading /telegraf body failed: %v", err)
+	}
+
+	var diskLine string
+	for _, line := range strings.Split(strings.TrimSpace(string(bodyBytes)), "\n") {
+		if strings.HasPrefix(line, "disk,disk_id=disk0 ") {
+			diskLine = line
+			break
+		}
+	}
+	if diskLine == "" {
+		t.Fatalf("expected a disk,disk_id=disk0 line, got:\n%s", bodyBytes)
+	}
+
+	fields := strings.Join(strings.Fields(diskLine)[:len(strings.Fields(diskLine))-1], " ")
+	want := "disk,disk_id=disk0 read_bytes=4096,read_latency_seconds_count=1,read_latency_seconds_sum=0.002,read_ops=1"
+	if fields != want {
+		t.Fatalf("disk line (minus timestamp) = %q, want %q", fields, want)
+	}
+}
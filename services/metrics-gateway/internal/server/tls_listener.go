@@ -0,0 +1,153 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"sync/atomic"
+	"syscall"
+)
+
+// TCPConfig configures the optional TCP+mTLS listener that runs alongside
+// the UDS listener, sharing the same grpc.Server, interceptor chain, and
+// shutdown lifecycle.
+type TCPConfig struct {
+	Addr string
+
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string
+
+	MinVersion        uint16
+	RequireClientCert bool
+
+	// AllowedIdentities, if non-empty, restricts TCP+mTLS connections to
+	// peers whose certificate CN or a SAN matches one of these values.
+	AllowedIdentities []string
+}
+
+// Enabled reports whether a TCP listen address was configured.
+func (c TCPConfig) Enabled() bool { return c.Addr != "" }
+
+func parseTLSMinVersion(s string) uint16 {
+	switch strings.TrimSpace(s) {
+	case "1.3":
+		return tls.VersionTLS13
+	case "1.2", "":
+		return tls.VersionTLS12
+	default:
+		return tls.VersionTLS12
+	}
+}
+
+func parseAllowedIdentities(s string) []string {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if id := strings.TrimSpace(part); id != "" {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// dynamicTLSConfig holds a *tls.Config behind an atomic pointer so it can be
+// swapped out on SIGHUP (certificate rotation) without dropping in-flight
+// streams: GetConfigForClient reads the current value per-handshake.
+type dynamicTLSConfig struct {
+	current atomic.Pointer[tls.Config]
+}
+
+func (d *dynamicTLSConfig) set(cfg *tls.Config) {
+	d.current.Store(cfg)
+}
+
+func (d *dynamicTLSConfig) GetConfigForClient(*tls.ClientHelloInfo) (*tls.Config, error) {
+	return d.current.Load(), nil
+}
+
+// newDynamicTLSConfig loads the initial TLS material for cfg and returns a
+// dynamicTLSConfig the caller should embed in a base *tls.Config via
+// GetConfigForClient, plus a stop func for the background SIGHUP watcher.
+func newDynamicTLSConfig(cfg TCPConfig) (*dynamicTLSConfig, func(), error) {
+	tlsCfg, err := loadTLSConfig(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dyn := &dynamicTLSConfig{}
+	dyn.set(tlsCfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	watchTLSReloadSIGHUP(ctx, cfg, dyn)
+
+	return dyn, cancel, nil
+}
+
+func loadTLSConfig(cfg TCPConfig) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load TLS keypair: %w", err)
+	}
+
+	tlsCfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   cfg.MinVersion,
+		ClientAuth:   tls.NoClientCert,
+	}
+
+	if cfg.ClientCAFile != "" {
+		caBytes, err := os.ReadFile(cfg.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read client CA file: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caBytes) {
+			return nil, fmt.Errorf("no certificates parsed from client CA file %s", cfg.ClientCAFile)
+		}
+		tlsCfg.ClientCAs = pool
+
+		if cfg.RequireClientCert {
+			tlsCfg.ClientAuth = tls.RequireAndVerifyClientCert
+		} else {
+			tlsCfg.ClientAuth = tls.VerifyClientCertIfGiven
+		}
+	}
+
+	return tlsCfg, nil
+}
+
+// watchTLSReloadSIGHUP reloads the certificate/CA material on every SIGHUP,
+// swapping it into dyn atomically. A reload failure is logged and the
+// previous TLS material is kept in place.
+func watchTLSReloadSIGHUP(ctx context.Context, cfg TCPConfig, dyn *dynamicTLSConfig) {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+
+	go func() {
+		defer signal.Stop(sighup)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sighup:
+				tlsCfg, err := loadTLSConfig(cfg)
+				if err != nil {
+					log.Printf("tls: reload failed, keeping previous certificate: %v", err)
+					continue
+				}
+				dyn.set(tlsCfg)
+			}
+		}
+	}()
+}
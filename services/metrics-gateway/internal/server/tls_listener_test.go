@@ -0,0 +1,70 @@
+package server
+
+import (
+	"crypto/tls"
+	"testing"
+)
+
+func TestParseTLSMinVersion(t *testing.T) {
+	if v := parseTLSMinVersion("1.3"); v != tls.VersionTLS13 {
+		t.Fatalf("expected TLS 1.3, got %v", v)
+	}
+	if v := parseTLSMinVersion("1.2"); v != tls.VersionTLS12 {
+		t.Fatalf("expected TLS 1.2, got %v", v)
+	}
+	if v := parseTLSMinVersion(""); v != tls.VersionTLS12 {
+		t.Fatalf("expected default TLS 1.2, got %v", v)
+	}
+}
+
+func TestParseAllowedIdentities(t *testing.T) {
+	if ids := parseAllowedIdentities(""); ids != nil {
+		t.Fatalf("expected nil for empty input, got %v", ids)
+	}
+
+	ids := parseAllowedIdentities("producer-a, producer-b ,, producer-c")
+	want := []string{"producer-a", "producer-b", "producer-c"}
+	if len(ids) != len(want) {
+		t.Fatalf("expected %v, got %v", want, ids)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, ids)
+		}
+	}
+}
+
+func TestDynamicTLSConfigSwapsAtomically(t *testing.T) {
+	dyn := &dynamicTLSConfig{}
+
+	first := &tls.Config{ServerName: "first"}
+	dyn.set(first)
+
+	got, err := dyn.GetConfigForClient(nil)
+	if err != nil {
+		t.Fatalf("GetConfigForClient: %v", err)
+	}
+	if got.ServerName != "first" {
+		t.Fatalf("expected first config, got %+v", got)
+	}
+
+	second := &tls.Config{ServerName: "second"}
+	dyn.set(second)
+
+	got, err = dyn.GetConfigForClient(nil)
+	if err != nil {
+		t.Fatalf("GetConfigForClient: %v", err)
+	}
+	if got.ServerName != "second" {
+		t.Fatalf("expected second config after swap, got %+v", got)
+	}
+}
+
+func TestTCPConfigEnabled(t *testing.T) {
+	if (TCPConfig{}).Enabled() {
+		t.Fatal("expected TCPConfig without Addr to be disabled")
+	}
+	if !(TCPConfig{Addr: ":9443"}).Enabled() {
+		t.Fatal("expected TCPConfig with Addr to be enabled")
+	}
+}
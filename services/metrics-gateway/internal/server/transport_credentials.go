@@ -0,0 +1,123 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// PeerCredInfo is the credentials.AuthInfo stashed for a UDS connection
+// whose SO_PEERCRED (Linux) / LOCAL_PEERCRED (BSD/macOS) identity was
+// extracted and, if an allow-list was configured, matched against it.
+type PeerCredInfo struct {
+	UID uint32
+	GID uint32
+	PID int32
+}
+
+// AuthType implements credentials.AuthInfo.
+func (PeerCredInfo) AuthType() string { return "uds-peercred" }
+
+// serverTransportCredentials is the single credentials.TransportCredentials
+// installed on the server's shared grpc.Server (see NewGRPCServer). gRPC
+// only takes one Creds per server, yet the UDS and TCP+mTLS listeners need
+// different handshakes, so this branches on the accepted connection's
+// concrete type:
+//   - *net.UnixConn: extract the peer's uid/gid/pid and, if an allow-list
+//     is configured, reject connections outside it.
+//   - *tls.Conn: the TCP+mTLS listener already wraps its net.Listener in
+//     TLS (see listenTCPWithTLS), so the handshake completes here and its
+//     ConnectionState is surfaced as credentials.TLSInfo, letting
+//     checkMTLSIdentity/checkAuth read the verified client certificate.
+//   - anything else: pass through with no AuthInfo.
+type serverTransportCredentials struct {
+	allowedUIDs map[uint32]struct{}
+	allowedGIDs map[uint32]struct{}
+}
+
+func newServerTransportCredentials(allowedUIDs, allowedGIDs []uint32) credentials.TransportCredentials {
+	c := &serverTransportCredentials{
+		allowedUIDs: make(map[uint32]struct{}, len(allowedUIDs)),
+		allowedGIDs: make(map[uint32]struct{}, len(allowedGIDs)),
+	}
+	for _, uid := range allowedUIDs {
+		c.allowedUIDs[uid] = struct{}{}
+	}
+	for _, gid := range allowedGIDs {
+		c.allowedGIDs[gid] = struct{}{}
+	}
+	return c
+}
+
+func (c *serverTransportCredentials) ClientHandshake(context.Context, string, net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return nil, nil, fmt.Errorf("serverTransportCredentials: client-side handshake is not supported")
+}
+
+func (c *serverTransportCredentials) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	switch typed := conn.(type) {
+	case *net.UnixConn:
+		return c.handshakeUnix(typed)
+	case *tls.Conn:
+		return handshakeTLS(typed)
+	default:
+		return conn, nil, nil
+	}
+}
+
+func (c *serverTransportCredentials) handshakeUnix(conn *net.UnixConn) (net.Conn, credentials.AuthInfo, error) {
+	cred, err := peerCredFromConn(conn)
+	if err != nil {
+		// SO_PEERCRED isn't available on every platform/kernel; don't fail
+		// closed for operators who never configured an allow-list.
+		if len(c.allowedUIDs) == 0 && len(c.allowedGIDs) == 0 {
+			return conn, nil, nil
+		}
+		return nil, nil, fmt.Errorf("read peer credentials: %w", err)
+	}
+
+	if !c.credAllowed(cred) {
+		return nil, nil, fmt.Errorf("uid %d gid %d is not in the UDS peer allow-list", cred.UID, cred.GID)
+	}
+	return conn, cred, nil
+}
+
+func (c *serverTransportCredentials) credAllowed(cred PeerCredInfo) bool {
+	if len(c.allowedUIDs) == 0 && len(c.allowedGIDs) == 0 {
+		return true
+	}
+	if _, ok := c.allowedUIDs[cred.UID]; ok {
+		return true
+	}
+	if _, ok := c.allowedGIDs[cred.GID]; ok {
+		return true
+	}
+	return false
+}
+
+func handshakeTLS(conn *tls.Conn) (net.Conn, credentials.AuthInfo, error) {
+	if err := conn.HandshakeContext(context.Background()); err != nil {
+		return nil, nil, fmt.Errorf("tls handshake: %w", err)
+	}
+	return conn, credentials.TLSInfo{State: conn.ConnectionState()}, nil
+}
+
+func (c *serverTransportCredentials) Info() credentials.ProtocolInfo {
+	return credentials.ProtocolInfo{SecurityProtocol: "uds-peercred-or-tls"}
+}
+
+func (c *serverTransportCredentials) Clone() credentials.TransportCredentials {
+	uids := make([]uint32, 0, len(c.allowedUIDs))
+	for uid := range c.allowedUIDs {
+		uids = append(uids, uid)
+	}
+	gids := make([]uint32, 0, len(c.allowedGIDs))
+	for gid := range c.allowedGIDs {
+		gids = append(gids, gid)
+	}
+	return newServerTransportCredentials(uids, gids)
+}
+
+func (c *serverTransportCredentials) OverrideServerName(string) error { return nil }
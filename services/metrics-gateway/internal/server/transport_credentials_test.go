@@ -0,0 +1,55 @@
+package server
+
+import (
+	"testing"
+
+	"google.golang.org/grpc/credentials"
+)
+
+func TestServerTransportCredentialsCredAllowed(t *testing.T) {
+	creds := newServerTransportCredentials([]uint32{1000}, []uint32{2000}).(*serverTransportCredentials)
+
+	cases := []struct {
+		name string
+		cred PeerCredInfo
+		want bool
+	}{
+		{"matching uid", PeerCredInfo{UID: 1000, GID: 9999}, true},
+		{"matching gid", PeerCredInfo{UID: 9999, GID: 2000}, true},
+		{"neither matches", PeerCredInfo{UID: 1, GID: 1}, false},
+	}
+
+	for _, tc := range cases {
+		if got := creds.credAllowed(tc.cred); got != tc.want {
+			t.Errorf("%s: credAllowed(%+v) = %v, want %v", tc.name, tc.cred, got, tc.want)
+		}
+	}
+}
+
+func TestServerTransportCredentialsNoAllowListAllowsAnyCred(t *testing.T) {
+	creds := newServerTransportCredentials(nil, nil).(*serverTransportCredentials)
+	if !creds.credAllowed(PeerCredInfo{UID: 12345, GID: 12345}) {
+		t.Fatal("expected an empty allow-list to accept any uid/gid")
+	}
+}
+
+func TestServerTransportCredentialsPassesThroughUnknownConnType(t *testing.T) {
+	creds := newServerTransportCredentials(nil, nil)
+	conn, authInfo, err := creds.ServerHandshake(nil)
+	if err != nil {
+		t.Fatalf("expected an unrecognized conn type to pass through unchanged, got %v", err)
+	}
+	if conn != nil || authInfo != nil {
+		t.Fatalf("expected the nil conn and no AuthInfo to be returned as-is, got conn=%v authInfo=%v", conn, authInfo)
+	}
+}
+
+func TestServerTransportCredentialsInfo(t *testing.T) {
+	creds := newServerTransportCredentials(nil, nil)
+	if got := creds.Info().SecurityProtocol; got == "" {
+		t.Fatal("expected a non-empty SecurityProtocol")
+	}
+	if _, ok := any(creds).(credentials.TransportCredentials); !ok {
+		t.Fatal("expected serverTransportCredentials to implement credentials.TransportCredentials")
+	}
+}
@@ -0,0 +1,179 @@
+package simulator
+
+import "time"
+
+// BuiltinWorkloads are the scenarios shipped with the gateway, selectable
+// by name via --scenario (e.g. --scenario small-random-io) without needing
+// an external YAML file.
+var BuiltinWorkloads = map[string]*Workload{
+	"small-random-io":  smallRandomIOWorkload(),
+	"large-sequential": largeSequentialWorkload(),
+	"degraded-rebuild": degradedRebuildWorkload(),
+	"flaky-disk":       flakyDiskWorkload(),
+}
+
+// smallRandomIOWorkload models a busy small-block OLTP-style host, ramping
+// up, holding steady, taking a short burst, then idling.
+func smallRandomIOWorkload() *Workload {
+	return &Workload{
+		Name: "small-random-io",
+		Disk: DiskWorkload{
+			Reads:     Distribution{Kind: "poisson", Lambda: 60},
+			Writes:    Distribution{Kind: "poisson", Lambda: 40},
+			ReadSize:  Distribution{Kind: "uniform", Min: 4096, Max: 16384},
+			WriteSize: Distribution{Kind: "uniform", Min: 4096, Max: 16384},
+			ErrorRate: 0.01,
+		},
+		Raid: RaidWorkload{
+			Reads:              Distribution{Kind: "poisson", Lambda: 200},
+			Writes:             Distribution{Kind: "poisson", Lambda: 150},
+			ReadSize:           Distribution{Kind: "uniform", Min: 16384, Max: 65536},
+			WriteSize:          Distribution{Kind: "uniform", Min: 16384, Max: 65536},
+			DegradeProbability: 0.02,
+
+			RebuildStartProbability: 0.3,
+			RebuildDuration:         Distribution{Kind: "uniform", Min: 60, Max: 180},
+		},
+		Fuse: FuseWorkload{
+			Reads:     Distribution{Kind: "poisson", Lambda: 300},
+			Writes:    Distribution{Kind: "poisson", Lambda: 200},
+			Opens:     Distribution{Kind: "poisson", Lambda: 100},
+			Fsyncs:    Distribution{Kind: "poisson", Lambda: 50},
+			ReadSize:  Distribution{Kind: "uniform", Min: 4096, Max: 65536},
+			WriteSize: Distribution{Kind: "uniform", Min: 4096, Max: 65536},
+			ErrorRate: 0.02,
+		},
+		Phases: []Phase{
+			{Name: "ramp-up", Duration: 30 * time.Second, Scale: 0.2},
+			{Name: "steady", Duration: 5 * time.Minute, Scale: 1.0},
+			{Name: "burst", Duration: 30 * time.Second, Scale: 3.0},
+			{Name: "idle", Duration: 1 * time.Minute, Scale: 0.05},
+		},
+	}
+}
+
+// largeSequentialWorkload models a throughput-bound streaming workload:
+// fewer, much larger ops, held at a constant rate.
+func largeSequentialWorkload() *Workload {
+	return &Workload{
+		Name: "large-sequential",
+		Disk: DiskWorkload{
+			Reads:     Distribution{Kind: "poisson", Lambda: 20},
+			Writes:    Distribution{Kind: "poisson", Lambda: 30},
+			ReadSize:  Distribution{Kind: "uniform", Min: 262144, Max: 1048576},
+			WriteSize: Distribution{Kind: "uniform", Min: 262144, Max: 1048576},
+			ErrorRate: 0.002,
+		},
+		Raid: RaidWorkload{
+			Reads:              Distribution{Kind: "poisson", Lambda: 60},
+			Writes:             Distribution{Kind: "poisson", Lambda: 80},
+			ReadSize:           Distribution{Kind: "uniform", Min: 524288, Max: 2097152},
+			WriteSize:          Distribution{Kind: "uniform", Min: 524288, Max: 2097152},
+			DegradeProbability: 0.005,
+
+			RebuildStartProbability: 0.2,
+			RebuildDuration:         Distribution{Kind: "uniform", Min: 180, Max: 600},
+		},
+		Fuse: FuseWorkload{
+			Reads:     Distribution{Kind: "poisson", Lambda: 40},
+			Writes:    Distribution{Kind: "poisson", Lambda: 50},
+			Opens:     Distribution{Kind: "poisson", Lambda: 10},
+			Fsyncs:    Distribution{Kind: "poisson", Lambda: 20},
+			ReadSize:  Distribution{Kind: "uniform", Min: 262144, Max: 1048576},
+			WriteSize: Distribution{Kind: "uniform", Min: 262144, Max: 1048576},
+			ErrorRate: 0.001,
+		},
+		Phases: []Phase{
+			{Name: "steady", Duration: 10 * time.Minute, Scale: 1.0},
+		},
+	}
+}
+
+// degradedRebuildWorkload models an array stuck resyncing: RAID reads spike
+// (the rebuild itself) while everything else stays at an ordinary rate.
+func degradedRebuildWorkload() *Workload {
+	return &Workload{
+		Name: "degraded-rebuild",
+		Disk: DiskWorkload{
+			Reads:     Distribution{Kind: "poisson", Lambda: 80},
+			Writes:    Distribution{Kind: "poisson", Lambda: 60},
+			ReadSize:  Distribution{Kind: "uniform", Min: 4096, Max: 65536},
+			WriteSize: Distribution{Kind: "uniform", Min: 4096, Max: 65536},
+			ErrorRate: 0.01,
+		},
+		Raid: RaidWorkload{
+			Reads:              Distribution{Kind: "poisson", Lambda: 500},
+			Writes:             Distribution{Kind: "poisson", Lambda: 100},
+			ReadSize:           Distribution{Kind: "uniform", Min: 16384, Max: 131072},
+			WriteSize:          Distribution{Kind: "uniform", Min: 16384, Max: 131072},
+			DegradeProbability: 0.6,
+
+			RebuildStartProbability: 0.9,
+			RebuildDuration:         Distribution{Kind: "uniform", Min: 300, Max: 900},
+
+			// A rebuild's reconstruction reads occasionally stall well past
+			// the normal fast path, so give this scenario's RAID latency a
+			// bimodal slow tail rather than the single-lognormal default.
+			Latency: LatencyWorkload{
+				Samples: 3,
+				Dist: LatencyDistribution{
+					Kind:            "bimodal",
+					Mean:            raidLatencyMeanDefault,
+					StdDev:          raidLatencyStdDevDefault,
+					SlowProbability: 0.1,
+					SlowMean:        -3.0,
+					SlowStdDev:      0.4,
+				},
+			},
+		},
+		Fuse: FuseWorkload{
+			Reads:     Distribution{Kind: "poisson", Lambda: 150},
+			Writes:    Distribution{Kind: "poisson", Lambda: 100},
+			Opens:     Distribution{Kind: "poisson", Lambda: 50},
+			Fsyncs:    Distribution{Kind: "poisson", Lambda: 30},
+			ReadSize:  Distribution{Kind: "uniform", Min: 4096, Max: 65536},
+			WriteSize: Distribution{Kind: "uniform", Min: 4096, Max: 65536},
+			ErrorRate: 0.01,
+		},
+		Phases: []Phase{
+			{Name: "steady", Duration: 10 * time.Minute, Scale: 1.0},
+		},
+	}
+}
+
+// flakyDiskWorkload models a marginal disk throwing errors well above the
+// other scenarios' background rate, without actually dropping its I/O rate.
+func flakyDiskWorkload() *Workload {
+	return &Workload{
+		Name: "flaky-disk",
+		Disk: DiskWorkload{
+			Reads:     Distribution{Kind: "poisson", Lambda: 60},
+			Writes:    Distribution{Kind: "poisson", Lambda: 40},
+			ReadSize:  Distribution{Kind: "uniform", Min: 4096, Max: 65536},
+			WriteSize: Distribution{Kind: "uniform", Min: 4096, Max: 65536},
+			ErrorRate: 0.15,
+		},
+		Raid: RaidWorkload{
+			Reads:              Distribution{Kind: "poisson", Lambda: 200},
+			Writes:             Distribution{Kind: "poisson", Lambda: 150},
+			ReadSize:           Distribution{Kind: "uniform", Min: 16384, Max: 65536},
+			WriteSize:          Distribution{Kind: "uniform", Min: 16384, Max: 65536},
+			DegradeProbability: 0.1,
+
+			RebuildStartProbability: 0.4,
+			RebuildDuration:         Distribution{Kind: "uniform", Min: 60, Max: 240},
+		},
+		Fuse: FuseWorkload{
+			Reads:     Distribution{Kind: "poisson", Lambda: 300},
+			Writes:    Distribution{Kind: "poisson", Lambda: 200},
+			Opens:     Distribution{Kind: "poisson", Lambda: 100},
+			Fsyncs:    Distribution{Kind: "poisson", Lambda: 50},
+			ReadSize:  Distribution{Kind: "uniform", Min: 4096, Max: 65536},
+			WriteSize: Distribution{Kind: "uniform", Min: 4096, Max: 65536},
+			ErrorRate: 0.1,
+		},
+		Phases: []Phase{
+			{Name: "steady", Duration: 10 * time.Minute, Scale: 1.0},
+		},
+	}
+}
@@ -0,0 +1,21 @@
+package simulator
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Collector populates metrics.AllMetrics on a timer. Simulator fabricates
+// activity; HostCollector and HybridCollector read it from the host;
+// Replayer feeds back a previously recorded trace.
+type Collector interface {
+	Start(ctx context.Context, wg *sync.WaitGroup, interval time.Duration)
+}
+
+var (
+	_ Collector = (*Simulator)(nil)
+	_ Collector = (*HostCollector)(nil)
+	_ Collector = (*HybridCollector)(nil)
+	_ Collector = (*Replayer)(nil)
+)
@@ -0,0 +1,333 @@
+package simulator
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	metricsPkg "metrics-gateway/internal/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+const (
+	defaultMdstatPath = "/proc/mdstat"
+)
+
+// diskSample is the cumulative counters gopsutil reports for one device.
+// HostCollector only ever has Add on the underlying CounterVecs, so it
+// keeps the previous sample around to turn these into per-tick deltas.
+type diskSample struct {
+	readCount, writeCount   uint64
+	readBytes, writeBytes   uint64
+	readTimeMS, writeTimeMS uint64
+}
+
+// HostCollector is a Collector that reads real activity from the host
+// instead of fabricating it: per-device disk I/O via gopsutil, RAID health
+// from /proc/mdstat, and process CPU/RSS via gopsutil's process package.
+// FUSE per-op counters aren't exposed by stock /sys/fs/fuse/connections or
+// /proc/self/mountstats, so tickFuse always errors here — HybridCollector
+// is how a deployment gets FUSE numbers without giving up the real disk
+// and RAID readings.
+type HostCollector struct {
+	metrics *metricsPkg.AllMetrics
+	diskIDs []string
+	raidIDs []string
+
+	mdstatPath string
+
+	mu        sync.Mutex
+	prevDisks map[string]diskSample
+
+	fuseUnavailableOnce sync.Once
+}
+
+// NewHostCollector returns a HostCollector reading disk stats for the given
+// kernel device names (e.g. "sda") and RAID health for the given md device
+// names (e.g. "md0") from /proc/mdstat.
+func NewHostCollector(metrics *metricsPkg.AllMetrics, diskIDs, raidIDs []string) *HostCollector {
+	return &HostCollector{
+		metrics:    metrics,
+		diskIDs:    diskIDs,
+		raidIDs:    raidIDs,
+		mdstatPath: defaultMdstatPath,
+		prevDisks:  map[string]diskSample{},
+	}
+}
+
+// Start runs the collector on interval until ctx is cancelled, registering
+// its goroutine on wg.
+func (c *HostCollector) Start(ctx context.Context, wg *sync.WaitGroup, interval time.Duration) {
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.tick()
+			}
+		}
+	}()
+}
+
+func (c *HostCollector) tick() {
+	if err := c.tickDisks(); err != nil {
+		log.Printf("simulator: host disk collection failed: %v", err)
+	}
+	if err := c.tickRaid(); err != nil {
+		log.Printf("simulator: host RAID collection failed: %v", err)
+	}
+	if err := c.tickFuse(); err != nil {
+		// A known, permanent limitation (see tickFuse's doc comment), not a
+		// transient failure, so log it once instead of every tick forever.
+		c.fuseUnavailableOnce.Do(func() {
+			log.Printf("simulator: host FUSE collection unavailable, will not retry: %v", err)
+		})
+	}
+	if err := c.tickProcess(); err != nil {
+		log.Printf("simulator: host process collection failed: %v", err)
+	}
+}
+
+func (c *HostCollector) tickDisks() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	counters, err := disk.IOCountersWithContext(ctx, c.diskIDs...)
+	if err != nil {
+		return fmt.Errorf("disk.IOCounters: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	m := c.metrics.Disks
+	for _, diskID := range c.diskIDs {
+		stat, ok := counters[diskID]
+		if !ok {
+			continue
+		}
+
+		cur := diskSample{
+			readCount:   stat.ReadCount,
+			writeCount:  stat.WriteCount,
+			readBytes:   stat.ReadBytes,
+			writeBytes:  stat.WriteBytes,
+			readTimeMS:  stat.ReadTime,
+			writeTimeMS: stat.WriteTime,
+		}
+		prev, seen := c.prevDisks[diskID]
+		c.prevDisks[diskID] = cur
+		if !seen {
+			continue // first sample has no prior baseline to diff against
+		}
+
+		dReads := counterDelta(cur.readCount, prev.readCount)
+		dWrites := counterDelta(cur.writeCount, prev.writeCount)
+
+		m.ReadOps.WithLabelValues(diskID).Add(float64(dReads))
+		m.WriteOps.WithLabelValues(diskID).Add(float64(dWrites))
+		m.ReadBytes.WithLabelValues(diskID).Add(float64(counterDelta(cur.readBytes, prev.readBytes)))
+		m.WriteBytes.WithLabelValues(diskID).Add(float64(counterDelta(cur.writeBytes, prev.writeBytes)))
+
+		if dReads > 0 {
+			avgReadLatency := float64(counterDelta(cur.readTimeMS, prev.readTimeMS)) / float64(dReads) / 1000
+			m.ReadLatency.WithLabelValues(diskID).Observe(avgReadLatency)
+		}
+		if dWrites > 0 {
+			avgWriteLatency := float64(counterDelta(cur.writeTimeMS, prev.writeTimeMS)) / float64(dWrites) / 1000
+			m.WriteLatency.WithLabelValues(diskID).Observe(avgWriteLatency)
+		}
+
+		m.QueueDepth.WithLabelValues(diskID).Set(float64(stat.IopsInProgress))
+	}
+
+	return nil
+}
+
+// counterDelta diffs two cumulative kernel counters, clamping to 0 instead
+// of underflowing when the counter has reset (e.g. the device was replaced
+// or the host rebooted between ticks).
+func counterDelta(cur, prev uint64) uint64 {
+	if cur < prev {
+		return 0
+	}
+	return cur - prev
+}
+
+// mdstatEntry is one RAID array's health as read from /proc/mdstat.
+type mdstatEntry struct {
+	degraded    bool
+	failedDisks int
+	rebuilding  bool
+	progress    float64 // 0-1; only meaningful when rebuilding is true
+}
+
+var (
+	mdstatStatusRE   = regexp.MustCompile(`\[([U_]+)\]`)
+	mdstatProgressRE = regexp.MustCompile(`(?:resync|recovery)\s*=\s*([\d.]+)%`)
+	mdstatFailedRE   = regexp.MustCompile(`\(F\)`)
+)
+
+// parseMdstat extracts the health of each array named in raidIDs from
+// /proc/mdstat's text format, e.g.:
+//
+//	md0 : active raid1 sdb1[1] sda1[0]
+//	      976630464 blocks super 1.2 [2/2] [UU]
+//
+//	md1 : active raid1 sdc1[2](F) sdb1[1] sda1[0]
+//	      1953511936 blocks super 1.2 [3/2] [UU_]
+//	      [=====>...............]  recovery = 27.5% (543214/1953511936) finish=100.5min speed=7566K/sec
+func parseMdstat(r io.Reader, raidIDs []string) (map[string]mdstatEntry, error) {
+	wanted := make(map[string]bool, len(raidIDs))
+	for _, id := range raidIDs {
+		wanted[id] = true
+	}
+
+	entries := map[string]mdstatEntry{}
+	scanner := bufio.NewScanner(r)
+
+	var current string
+	var entry mdstatEntry
+	flush := func() {
+		if current != "" && wanted[current] {
+			entries[current] = entry
+		}
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if name, ok := mdstatDeviceName(line); ok {
+			flush()
+			current = name
+			entry = mdstatEntry{failedDisks: len(mdstatFailedRE.FindAllString(line, -1))}
+			continue
+		}
+
+		if current == "" {
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			flush()
+			current = ""
+			continue
+		}
+
+		if m := mdstatStatusRE.FindStringSubmatch(line); m != nil && strings.Contains(m[1], "_") {
+			entry.degraded = true
+		}
+		if m := mdstatProgressRE.FindStringSubmatch(line); m != nil {
+			entry.rebuilding = true
+			if pct, err := strconv.ParseFloat(m[1], 64); err == nil {
+				entry.progress = pct / 100
+			}
+		}
+	}
+	flush()
+
+	return entries, scanner.Err()
+}
+
+func mdstatDeviceName(line string) (string, bool) {
+	if !strings.HasPrefix(line, "md") {
+		return "", false
+	}
+	fields := strings.Fields(line)
+	if len(fields) < 2 || fields[1] != ":" {
+		return "", false
+	}
+	return fields[0], true
+}
+
+func (c *HostCollector) tickRaid() error {
+	f, err := os.Open(c.mdstatPath)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", c.mdstatPath, err)
+	}
+	defer f.Close()
+
+	entries, err := parseMdstat(f, c.raidIDs)
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", c.mdstatPath, err)
+	}
+
+	m := c.metrics.Raid
+	for _, raidID := range c.raidIDs {
+		entry, ok := entries[raidID]
+		if !ok {
+			continue // not assembled, or named differently than expected
+		}
+
+		setBool(m.DegradedState.WithLabelValues(raidID), entry.degraded)
+		m.FailedDisks.WithLabelValues(raidID).Set(float64(entry.failedDisks))
+		setBool(m.RebuildInProgress.WithLabelValues(raidID), entry.rebuilding)
+
+		// mdstat reports one resync/recovery percentage per array
+		// regardless of RAID level, so unlike the Simulator this isn't
+		// gated to "raid1" specifically.
+		if entry.rebuilding {
+			m.Raid1Resync.WithLabelValues(raidID).Set(entry.progress)
+		} else {
+			m.Raid1Resync.WithLabelValues(raidID).Set(0)
+		}
+	}
+
+	return nil
+}
+
+func setBool(g prometheus.Gauge, v bool) {
+	if v {
+		g.Set(1)
+		return
+	}
+	g.Set(0)
+}
+
+// tickFuse always errors: stock /sys/fs/fuse/connections and
+// /proc/self/mountstats don't expose per-op FUSE counters, so there's
+// nothing real to read. HybridCollector falls back to the Simulator's
+// fabricated FUSE numbers when this happens.
+func (c *HostCollector) tickFuse() error {
+	return fmt.Errorf("host FUSE op counters are not available from this kernel")
+}
+
+func (c *HostCollector) tickProcess() error {
+	p, err := process.NewProcess(int32(os.Getpid()))
+	if err != nil {
+		return fmt.Errorf("process.NewProcess: %w", err)
+	}
+
+	times, err := p.Times()
+	if err != nil {
+		return fmt.Errorf("process times: %w", err)
+	}
+	c.metrics.Process.CPUSeconds.Set(times.User + times.System)
+
+	mem, err := p.MemoryInfo()
+	if err != nil {
+		return fmt.Errorf("process memory info: %w", err)
+	}
+	c.metrics.Process.ResidentMemory.Set(float64(mem.RSS))
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package simulator
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+const sampleMdstat = `Personalities : [raid1] [raid6] [raid5] [raid4]
+md0 : active raid1 sdb1[1] sda1[0]
+      976630464 blocks super 1.2 [2/2] [UU]
+
+md1 : active raid1 sdc1[2](F) sdb1[1] sda1[0]
+      1953511936 blocks super 1.2 [3/2] [UU_]
+      [=====>...............]  recovery = 27.5% (543214/1953511936) finish=100.5min speed=7566K/sec
+
+unused devices: <none>
+`
+
+func TestParseMdstatHealthyArray(t *testing.T) {
+	entries, err := parseMdstat(strings.NewReader(sampleMdstat), []string{"md0"})
+	if err != nil {
+		t.Fatalf("parseMdstat: %v", err)
+	}
+
+	entry, ok := entries["md0"]
+	if !ok {
+		t.Fatal("expected an entry for md0")
+	}
+	if entry.degraded || entry.rebuilding || entry.failedDisks != 0 {
+		t.Fatalf("expected a healthy, non-rebuilding md0, got %+v", entry)
+	}
+}
+
+func TestParseMdstatDegradedRebuildingArray(t *testing.T) {
+	entries, err := parseMdstat(strings.NewReader(sampleMdstat), []string{"md1"})
+	if err != nil {
+		t.Fatalf("parseMdstat: %v", err)
+	}
+
+	entry, ok := entries["md1"]
+	if !ok {
+		t.Fatal("expected an entry for md1")
+	}
+	if !entry.degraded {
+		t.Fatal("expected md1 to be degraded")
+	}
+	if entry.failedDisks != 1 {
+		t.Fatalf("expected 1 failed disk, got %d", entry.failedDisks)
+	}
+	if !entry.rebuilding {
+		t.Fatal("expected md1 to be rebuilding")
+	}
+	if entry.progress != 0.275 {
+		t.Fatalf("expected progress 0.275, got %f", entry.progress)
+	}
+}
+
+func TestParseMdstatIgnoresUnrequestedArrays(t *testing.T) {
+	entries, err := parseMdstat(strings.NewReader(sampleMdstat), []string{"md2"})
+	if err != nil {
+		t.Fatalf("parseMdstat: %v", err)
+	}
+	if _, ok := entries["md0"]; ok {
+		t.Fatal("expected md0 to be excluded since it wasn't requested")
+	}
+	if len(entries) != 0 {
+		t.Fatalf("expected no entries for an array that never appears in mdstat, got %+v", entries)
+	}
+}
+
+func TestTickLogsFuseUnavailableOnlyOnce(t *testing.T) {
+	_, all := newTestSimulator(t)
+	c := NewHostCollector(all, nil, nil)
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	c.tick()
+	c.tick()
+	c.tick()
+
+	if got := strings.Count(buf.String(), "host FUSE collection unavailable"); got != 1 {
+		t.Fatalf("expected FUSE unavailability logged exactly once across 3 ticks, got %d", got)
+	}
+}
+
+func TestCounterDelta(t *testing.T) {
+	if got := counterDelta(10, 4); got != 6 {
+		t.Fatalf("counterDelta(10, 4) = %d, want 6", got)
+	}
+	if got := counterDelta(2, 9); got != 0 {
+		t.Fatalf("counterDelta(2, 9) = %d, want 0 (counter reset)", got)
+	}
+}
@@ -0,0 +1,78 @@
+package simulator
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// HybridCollector prefers HostCollector's real readings, falling back to
+// Simulator on a per-subsystem basis whenever the host collector can't
+// read that subsystem (most commonly FUSE, which HostCollector always
+// fails to read — see HostCollector.tickFuse). This keeps every metric
+// moving even on a host where only some of the real data is available.
+type HybridCollector struct {
+	host *HostCollector
+	sim  *Simulator
+
+	fuseFallbackOnce sync.Once
+}
+
+// NewHybridCollector pairs a HostCollector with a Simulator driving the
+// same AllMetrics, disk IDs, and RAID IDs.
+func NewHybridCollector(host *HostCollector, sim *Simulator) *HybridCollector {
+	return &HybridCollector{host: host, sim: sim}
+}
+
+// Start runs the collector on interval until ctx is cancelled, registering
+// its goroutine on wg.
+func (c *HybridCollector) Start(ctx context.Context, wg *sync.WaitGroup, interval time.Duration) {
+	c.sim.tickInterval = interval
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.tick()
+			}
+		}
+	}()
+}
+
+func (c *HybridCollector) tick() {
+	// The sim's per-subsystem simulate* methods are called directly below
+	// rather than through sim.tick(), so its simClock (which raidState
+	// rebuild progress is measured against) has to be advanced here instead.
+	c.sim.advanceClock()
+
+	if err := c.host.tickDisks(); err != nil {
+		log.Printf("simulator: falling back to simulated disk metrics: %v", err)
+		c.sim.simulateDisks(1.0)
+	}
+	if err := c.host.tickRaid(); err != nil {
+		log.Printf("simulator: falling back to simulated RAID metrics: %v", err)
+		c.sim.simulateRaid(1.0)
+	}
+	if err := c.host.tickFuse(); err != nil {
+		// tickFuse is a known, permanent limitation (see HostCollector.tickFuse),
+		// not a transient failure, so only log the fallback once instead of
+		// spamming it on every tick forever.
+		c.fuseFallbackOnce.Do(func() {
+			log.Printf("simulator: falling back to simulated FUSE metrics (will not log again): %v", err)
+		})
+		c.sim.simulateFuse(1.0)
+	}
+	if err := c.host.tickProcess(); err != nil {
+		log.Printf("simulator: falling back to simulated process metrics: %v", err)
+		c.sim.simulateProcess()
+	}
+}
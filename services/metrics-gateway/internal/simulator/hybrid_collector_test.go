@@ -0,0 +1,32 @@
+package simulator
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHybridTickLogsFuseFallbackOnlyOnceAndAdvancesSimClock(t *testing.T) {
+	sim, all := newTestSimulator(t)
+	sim.tickInterval = time.Second
+	host := NewHostCollector(all, nil, nil)
+	c := NewHybridCollector(host, sim)
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	c.tick()
+	c.tick()
+	c.tick()
+
+	if got := strings.Count(buf.String(), "falling back to simulated FUSE metrics"); got != 1 {
+		t.Fatalf("expected FUSE fallback logged exactly once across 3 ticks, got %d", got)
+	}
+	if sim.simClock != 3*sim.tickInterval {
+		t.Fatalf("expected simClock to advance by 3 ticks, got %v", sim.simClock)
+	}
+}
@@ -0,0 +1,152 @@
+package simulator
+
+import (
+	"time"
+
+	metricsPkg "metrics-gateway/internal/metrics"
+)
+
+// raidPhase is where a single RAID array sits in its health lifecycle.
+type raidPhase int
+
+const (
+	raidHealthy raidPhase = iota
+	raidDegraded
+	raidRebuilding
+	raidResyncing
+	// raidFailed is reached only via a forced fault (see faultinjector
+	// cascading rules): the array stays down for as long as the fault is
+	// forced, and on the first tick after it clears falls back to
+	// raidDegraded instead of healing on its own.
+	raidFailed
+)
+
+// raidState tracks one RAID array's health lifecycle across ticks, so
+// RebuildInProgress/Raid1Resync move as a consequence of elapsed time
+// rather than being rolled fresh (and independently) every tick.
+//
+// rebuildStart is measured against the Simulator's own tick-driven
+// simClock rather than wall-clock time, so NewSimulatorWithSeed's
+// determinism guarantee (same seed + tick count => same state) holds
+// regardless of how fast ticks actually arrive.
+type raidState struct {
+	phase                   raidPhase
+	failedDisks             int
+	rebuildStart            time.Duration
+	rebuildDurationEstimate time.Duration
+	progress                float64
+}
+
+// rebuildPhaseFor picks raidResyncing for RAID1 (matching mdstat's own
+// terminology for a mirror catching up) and raidRebuilding for anything
+// else (a real, rather than resync, reconstruction from parity/mirrors).
+func rebuildPhaseFor(raid string) raidPhase {
+	if raid == "raid1" {
+		return raidResyncing
+	}
+	return raidRebuilding
+}
+
+// tickRaidHealth advances raid's state machine by one tick and writes the
+// resulting health gauges. degradeProbability is the per-tick chance a
+// healthy array starts degrading; rebuildStartProbability is the per-tick
+// chance a degraded array begins rebuilding; rebuildDuration estimates how
+// long a newly started rebuild will take, against which progress is
+// measured as elapsed/estimate (plus small jitter, never moving backwards).
+// forceFailed overrides the state machine with a faultinjector-driven
+// failure (e.g. a cascading 2-disks-down-within-60s rule), abandoning any
+// rebuild in progress: once it turns false the array is plain degraded, and
+// a later rebuild starts again from zero progress.
+func (s *Simulator) tickRaidHealth(m *metricsPkg.RaidMetrics, raid string, degradeProbability, rebuildStartProbability float64, rebuildDuration time.Duration, forceFailed bool) {
+	st := s.raidStates[raid]
+	if st == nil {
+		st = &raidState{phase: raidHealthy}
+		s.raidStates[raid] = st
+	}
+
+	if forceFailed {
+		if st.failedDisks < 2 {
+			st.failedDisks = 2
+		}
+		st.phase = raidFailed
+		s.writeRaidHealth(m, raid, st)
+		return
+	}
+
+	switch st.phase {
+	case raidFailed:
+		// The cascading condition that forced this array down has
+		// cleared; fall back to a plain degraded state so a rebuild can
+		// resume normally instead of silently healing.
+		st.phase = raidDegraded
+	case raidHealthy:
+		if s.rnd.Float64() < degradeProbability {
+			st.phase = raidDegraded
+			st.failedDisks = 1 + s.rnd.Intn(2)
+		}
+	case raidDegraded:
+		if s.rnd.Float64() < rebuildStartProbability {
+			st.phase = rebuildPhaseFor(raid)
+			st.rebuildStart = s.simClock
+			st.rebuildDurationEstimate = rebuildDuration
+			st.progress = 0
+		}
+	case raidRebuilding, raidResyncing:
+		elapsed := s.simClock - st.rebuildStart
+		jitter := (s.rnd.Float64() - 0.5) * 0.02
+		next := float64(elapsed)/float64(st.rebuildDurationEstimate) + jitter
+		if next > st.progress {
+			st.progress = next
+		}
+		if st.progress >= 1 {
+			st.phase = raidHealthy
+			st.failedDisks = 0
+			st.progress = 0
+		}
+	}
+
+	s.writeRaidHealth(m, raid, st)
+}
+
+func (s *Simulator) writeRaidHealth(m *metricsPkg.RaidMetrics, raid string, st *raidState) {
+	switch st.phase {
+	case raidHealthy:
+		m.DegradedState.WithLabelValues(raid).Set(0)
+		m.FailedDisks.WithLabelValues(raid).Set(0)
+		m.RebuildInProgress.WithLabelValues(raid).Set(0)
+		if raid == "raid1" {
+			m.Raid1Resync.WithLabelValues(raid).Set(0)
+		}
+	case raidDegraded:
+		m.DegradedState.WithLabelValues(raid).Set(1)
+		m.FailedDisks.WithLabelValues(raid).Set(float64(st.failedDisks))
+		m.RebuildInProgress.WithLabelValues(raid).Set(0)
+	case raidFailed:
+		m.DegradedState.WithLabelValues(raid).Set(1)
+		m.FailedDisks.WithLabelValues(raid).Set(float64(st.failedDisks))
+		m.RebuildInProgress.WithLabelValues(raid).Set(0)
+		if raid == "raid1" {
+			m.Raid1Resync.WithLabelValues(raid).Set(0)
+		}
+	case raidRebuilding, raidResyncing:
+		progress := st.progress
+		if progress > 1 {
+			progress = 1
+		}
+
+		m.DegradedState.WithLabelValues(raid).Set(1)
+		m.FailedDisks.WithLabelValues(raid).Set(float64(st.failedDisks))
+		m.RebuildInProgress.WithLabelValues(raid).Set(1)
+		if raid == "raid1" {
+			m.Raid1Resync.WithLabelValues(raid).Set(progress)
+		}
+	}
+}
+
+// rebuilding reports whether raid is currently mid-rebuild/resync, for
+// callers that need to scale other metrics (e.g. RAID3 parity reads) to
+// the rebuild's throughput.
+func (s *Simulator) rebuilding(raid string) bool {
+	st := s.raidStates[raid]
+	return st != nil && (st.phase == raidRebuilding || st.phase == raidResyncing)
+}
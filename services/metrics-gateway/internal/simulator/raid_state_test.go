@@ -0,0 +1,108 @@
+package simulator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestTickRaidHealthProgressesMonotonicallyThenHeals(t *testing.T) {
+	sim, all := newTestSimulator(t)
+	m := all.Raid
+
+	// Force the array straight into a rebuild with a short, fixed estimate
+	// so the test doesn't depend on how many ticks the transition rolls
+	// take.
+	sim.raidStates["raid1"] = &raidState{phase: raidResyncing, failedDisks: 1, rebuildDurationEstimate: 5 * time.Second}
+
+	var last float64
+	for i := 0; i < 4; i++ {
+		sim.simClock += sim.tickInterval
+		sim.tickRaidHealth(m, "raid1", 0, 0, 5*time.Second, false)
+
+		cur := testutil.ToFloat64(m.Raid1Resync.WithLabelValues("raid1"))
+		if cur < last {
+			t.Fatalf("tick %d: Raid1Resync went backwards: %f -> %f", i, last, cur)
+		}
+		last = cur
+
+		if testutil.ToFloat64(m.RebuildInProgress.WithLabelValues("raid1")) != 1 {
+			t.Fatalf("tick %d: expected RebuildInProgress=1 mid-rebuild", i)
+		}
+	}
+
+	// tickInterval defaults to 1s (newTestSimulator never calls Start), so
+	// 5 more ticks exceeds the 5s estimate and the array should heal.
+	for i := 0; i < 5; i++ {
+		sim.simClock += sim.tickInterval
+		sim.tickRaidHealth(m, "raid1", 0, 0, 5*time.Second, false)
+	}
+
+	if got := testutil.ToFloat64(m.RebuildInProgress.WithLabelValues("raid1")); got != 0 {
+		t.Fatalf("expected rebuild to complete and RebuildInProgress to return to 0, got %f", got)
+	}
+	if got := testutil.ToFloat64(m.DegradedState.WithLabelValues("raid1")); got != 0 {
+		t.Fatalf("expected DegradedState to clear once healed, got %f", got)
+	}
+}
+
+func TestTickRaidHealthDegradedDoesNotReportRebuilding(t *testing.T) {
+	sim, all := newTestSimulator(t)
+	m := all.Raid
+
+	sim.raidStates["raid0"] = &raidState{phase: raidDegraded, failedDisks: 1}
+
+	// rebuildStartProbability 0 keeps it from transitioning this tick.
+	sim.tickRaidHealth(m, "raid0", 0, 0, time.Minute, false)
+
+	if got := testutil.ToFloat64(m.DegradedState.WithLabelValues("raid0")); got != 1 {
+		t.Fatalf("expected DegradedState=1 while degraded, got %f", got)
+	}
+	if got := testutil.ToFloat64(m.RebuildInProgress.WithLabelValues("raid0")); got != 0 {
+		t.Fatalf("expected RebuildInProgress=0 while merely degraded, got %f", got)
+	}
+}
+
+func TestTickRaidHealthForceFailedOverridesRebuildThenFallsBackToDegraded(t *testing.T) {
+	sim, all := newTestSimulator(t)
+	m := all.Raid
+
+	sim.raidStates["raid3"] = &raidState{phase: raidRebuilding, failedDisks: 1, rebuildDurationEstimate: 5 * time.Second}
+
+	sim.simClock += sim.tickInterval
+	sim.tickRaidHealth(m, "raid3", 0, 0, 5*time.Second, true)
+
+	if got := testutil.ToFloat64(m.FailedDisks.WithLabelValues("raid3")); got != 2 {
+		t.Fatalf("expected forceFailed to report 2 failed disks, got %f", got)
+	}
+	if got := testutil.ToFloat64(m.RebuildInProgress.WithLabelValues("raid3")); got != 0 {
+		t.Fatalf("expected RebuildInProgress=0 while force-failed, got %f", got)
+	}
+
+	// Once the cascading condition clears, the array should fall back to a
+	// plain degraded state rather than silently resuming the old rebuild.
+	sim.tickRaidHealth(m, "raid3", 0, 0, 5*time.Second, false)
+
+	if got := testutil.ToFloat64(m.DegradedState.WithLabelValues("raid3")); got != 1 {
+		t.Fatalf("expected DegradedState=1 after force-failed clears, got %f", got)
+	}
+	if got := testutil.ToFloat64(m.RebuildInProgress.WithLabelValues("raid3")); got != 0 {
+		t.Fatalf("expected rebuild not to silently resume after force-failed clears, got %f", got)
+	}
+}
+
+func TestRaid3RebuildAddsParityReads(t *testing.T) {
+	sim, all := newTestSimulator(t)
+	m := all.Raid
+
+	sim.raidStates["raid3"] = &raidState{phase: raidRebuilding, failedDisks: 1, rebuildDurationEstimate: time.Hour}
+
+	before := testutil.ToFloat64(m.Raid3ParityReads.WithLabelValues("raid3"))
+	sim.updateRaidSpecificMetrics(m, "raid3")
+	after := testutil.ToFloat64(m.Raid3ParityReads.WithLabelValues("raid3"))
+
+	if after-before < float64(len(sim.diskIDs))*100 {
+		t.Fatalf("expected a rebuild-sized jump in Raid3ParityReads, got delta %f", after-before)
+	}
+}
@@ -0,0 +1,150 @@
+package simulator
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	metricsPkg "metrics-gateway/internal/metrics"
+)
+
+// applier applies one metricSample to the AllMetrics series it names.
+type applier func(m *metricsPkg.AllMetrics, s metricSample)
+
+// replayTargets maps every metric name trace.go can emit to the function
+// that applies its delta to AllMetrics. It's a closed list (rather than
+// reflection over field/label names) because client_golang's CounterVec
+// and friends don't expose their label schema generically.
+var replayTargets = map[string]applier{
+	"disk_read_ops":               func(m *metricsPkg.AllMetrics, s metricSample) { m.Disks.ReadOps.WithLabelValues(s.Labels["disk_id"]).Add(s.Value) },
+	"disk_write_ops":              func(m *metricsPkg.AllMetrics, s metricSample) { m.Disks.WriteOps.WithLabelValues(s.Labels["disk_id"]).Add(s.Value) },
+	"disk_read_bytes":             func(m *metricsPkg.AllMetrics, s metricSample) { m.Disks.ReadBytes.WithLabelValues(s.Labels["disk_id"]).Add(s.Value) },
+	"disk_write_bytes":            func(m *metricsPkg.AllMetrics, s metricSample) { m.Disks.WriteBytes.WithLabelValues(s.Labels["disk_id"]).Add(s.Value) },
+	"disk_read_latency_seconds":   func(m *metricsPkg.AllMetrics, s metricSample) { observeHistogramDelta(m.Disks.ReadLatency.WithLabelValues(s.Labels["disk_id"]), s) },
+	"disk_write_latency_seconds":  func(m *metricsPkg.AllMetrics, s metricSample) { observeHistogramDelta(m.Disks.WriteLatency.WithLabelValues(s.Labels["disk_id"]), s) },
+	"disk_queue_depth":            func(m *metricsPkg.AllMetrics, s metricSample) { m.Disks.QueueDepth.WithLabelValues(s.Labels["disk_id"]).Set(s.Value) },
+	"disk_errors":                 func(m *metricsPkg.AllMetrics, s metricSample) { m.Disks.Errors.WithLabelValues(s.Labels["disk_id"]).Add(s.Value) },
+	"raid_read_ops":                func(m *metricsPkg.AllMetrics, s metricSample) { m.Raid.ReadOps.WithLabelValues(s.Labels["raid"]).Add(s.Value) },
+	"raid_write_ops":               func(m *metricsPkg.AllMetrics, s metricSample) { m.Raid.WriteOps.WithLabelValues(s.Labels["raid"]).Add(s.Value) },
+	"raid_read_bytes":              func(m *metricsPkg.AllMetrics, s metricSample) { m.Raid.ReadBytes.WithLabelValues(s.Labels["raid"]).Add(s.Value) },
+	"raid_write_bytes":             func(m *metricsPkg.AllMetrics, s metricSample) { m.Raid.WriteBytes.WithLabelValues(s.Labels["raid"]).Add(s.Value) },
+	"raid_read_latency_seconds":    func(m *metricsPkg.AllMetrics, s metricSample) { observeHistogramDelta(m.Raid.ReadLatency.WithLabelValues(s.Labels["raid"]), s) },
+	"raid_write_latency_seconds":   func(m *metricsPkg.AllMetrics, s metricSample) { observeHistogramDelta(m.Raid.WriteLatency.WithLabelValues(s.Labels["raid"]), s) },
+	"raid1_reads_from_disk":        func(m *metricsPkg.AllMetrics, s metricSample) { m.Raid.Raid1ReadsFromDisk.WithLabelValues(s.Labels["raid"], s.Labels["disk_id"]).Add(s.Value) },
+	"raid1_resync_progress":        func(m *metricsPkg.AllMetrics, s metricSample) { m.Raid.Raid1Resync.WithLabelValues(s.Labels["raid"]).Set(s.Value) },
+	"raid3_parity_reads":           func(m *metricsPkg.AllMetrics, s metricSample) { m.Raid.Raid3ParityReads.WithLabelValues(s.Labels["raid"]).Add(s.Value) },
+	"raid3_parity_writes":          func(m *metricsPkg.AllMetrics, s metricSample) { m.Raid.Raid3ParityWrites.WithLabelValues(s.Labels["raid"]).Add(s.Value) },
+	"raid3_partial_stripe_writes":  func(m *metricsPkg.AllMetrics, s metricSample) { m.Raid.Raid3PartialStripe.WithLabelValues(s.Labels["raid"]).Add(s.Value) },
+	"raid_degraded_state":          func(m *metricsPkg.AllMetrics, s metricSample) { m.Raid.DegradedState.WithLabelValues(s.Labels["raid"]).Set(s.Value) },
+	"raid_failed_disks":            func(m *metricsPkg.AllMetrics, s metricSample) { m.Raid.FailedDisks.WithLabelValues(s.Labels["raid"]).Set(s.Value) },
+	"raid_rebuild_in_progress":     func(m *metricsPkg.AllMetrics, s metricSample) { m.Raid.RebuildInProgress.WithLabelValues(s.Labels["raid"]).Set(s.Value) },
+	"fuse_read_ops":                func(m *metricsPkg.AllMetrics, s metricSample) { m.Fuse.ReadOps.Add(s.Value) },
+	"fuse_write_ops":               func(m *metricsPkg.AllMetrics, s metricSample) { m.Fuse.WriteOps.Add(s.Value) },
+	"fuse_open_ops":                func(m *metricsPkg.AllMetrics, s metricSample) { m.Fuse.OpenOps.Add(s.Value) },
+	"fuse_fsync_ops":                func(m *metricsPkg.AllMetrics, s metricSample) { m.Fuse.FsyncOps.Add(s.Value) },
+	"fuse_read_bytes":              func(m *metricsPkg.AllMetrics, s metricSample) { m.Fuse.ReadBytes.Add(s.Value) },
+	"fuse_write_bytes":             func(m *metricsPkg.AllMetrics, s metricSample) { m.Fuse.WriteBytes.Add(s.Value) },
+	"fuse_read_latency_seconds":    func(m *metricsPkg.AllMetrics, s metricSample) { observeHistogramDelta(m.Fuse.ReadLatency, s) },
+	"fuse_write_latency_seconds":   func(m *metricsPkg.AllMetrics, s metricSample) { observeHistogramDelta(m.Fuse.WriteLatency, s) },
+	"fuse_errors":                  func(m *metricsPkg.AllMetrics, s metricSample) { m.Fuse.Errors.Add(s.Value) },
+	"process_cpu_seconds":          func(m *metricsPkg.AllMetrics, s metricSample) { m.Process.CPUSeconds.Set(s.Value) },
+	"process_resident_memory":      func(m *metricsPkg.AllMetrics, s metricSample) { m.Process.ResidentMemory.Set(s.Value) },
+}
+
+// observeHistogramDelta reproduces a histogram's recorded Sum and Count for
+// one tick by calling Observe CountDelta times with their average. The
+// original per-observation values (and thus the exact bucket distribution)
+// aren't recoverable from a Gather() diff, so this is an approximation —
+// good enough to replay alert rules built on rate()/sum() but not a
+// byte-identical reconstruction.
+func observeHistogramDelta(h prometheus.Observer, s metricSample) {
+	if s.CountDelta == 0 {
+		return
+	}
+	avg := s.SumDelta / float64(s.CountDelta)
+	for i := uint64(0); i < s.CountDelta; i++ {
+		h.Observe(avg)
+	}
+}
+
+// Replayer is a Collector that feeds a previously recorded trace file into
+// AllMetrics instead of generating new activity, so a captured incident or
+// golden scenario can be replayed deterministically.
+type Replayer struct {
+	metrics *metricsPkg.AllMetrics
+	path    string
+}
+
+// NewReplayer returns a Replayer that will read path (as written by a
+// Recorder) when started.
+func NewReplayer(metrics *metricsPkg.AllMetrics, path string) *Replayer {
+	return &Replayer{metrics: metrics, path: path}
+}
+
+// Start opens the trace file and applies one TickTrace per interval until
+// the file is exhausted or ctx is cancelled, registering its goroutine on
+// wg. interval is ignored if the caller passed zero; ticks are otherwise
+// paced the same as any other Collector so downstream scrapes see a
+// realistic cadence of change.
+func (r *Replayer) Start(ctx context.Context, wg *sync.WaitGroup, interval time.Duration) {
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		f, err := os.Open(r.path)
+		if err != nil {
+			log.Printf("simulator: replay open error: %v", err)
+			return
+		}
+		defer f.Close()
+
+		dec := json.NewDecoder(f)
+
+		var tick <-chan time.Time
+		if interval > 0 {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			tick = ticker.C
+		} else {
+			unpaced := make(chan time.Time)
+			close(unpaced)
+			tick = unpaced
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-tick:
+				trace, err := decodeTrace(dec)
+				if err == io.EOF {
+					log.Printf("simulator: replay of %s finished", r.path)
+					return
+				}
+				if err != nil {
+					log.Printf("simulator: replay error: %v", err)
+					return
+				}
+				r.applyTrace(trace)
+			}
+		}
+	}()
+}
+
+func (r *Replayer) applyTrace(trace TickTrace) {
+	for _, s := range trace.Samples {
+		apply, ok := replayTargets[s.Name]
+		if !ok {
+			log.Printf("simulator: replay: no target for metric %q, skipping", s.Name)
+			continue
+		}
+		apply(r.metrics, s)
+	}
+}
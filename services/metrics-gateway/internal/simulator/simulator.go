@@ -0,0 +1,371 @@
+// Package simulator populates metrics.AllMetrics with synthetic disk, RAID,
+// FUSE, and process activity, so the gateway can be exercised and demoed
+// without a real Stripe/RAID deployment behind it.
+package simulator
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"metrics-gateway/internal/faultinjector"
+	metricsPkg "metrics-gateway/internal/metrics"
+)
+
+// Simulator is a Collector that fabricates plausible I/O activity on a
+// timer, rather than reading it from the host.
+type Simulator struct {
+	metrics *metricsPkg.AllMetrics
+	diskIDs []string
+	raidIDs []string
+
+	rnd         *rand.Rand
+	cpuSeconds  float64
+	memoryBytes float64
+
+	workload *Workload
+
+	// simClock is a tick-driven clock (advanced by tickInterval on every
+	// tick, regardless of wall-clock time) that raidState rebuild progress
+	// is measured against, so NewSimulatorWithSeed's determinism holds
+	// regardless of how fast ticks actually arrive.
+	simClock     time.Duration
+	tickInterval time.Duration
+
+	raidStates map[string]*raidState
+	faults     *faultinjector.FaultSchedule
+}
+
+// Default lognormal parameters (mean/stddev of the underlying normal, in
+// log-seconds) approximating each subsystem's old hardcoded uniform
+// latency ranges, used whenever a workload doesn't configure its own
+// Latency.Dist.
+const (
+	diskLatencyMeanDefault, diskLatencyStdDevDefault = -5.95, 0.5
+	raidLatencyMeanDefault, raidLatencyStdDevDefault = -6.12, 0.5
+	fuseLatencyMeanDefault, fuseLatencyStdDevDefault = -6.38, 0.4
+)
+
+// sampleLatencies draws n.samples() observations from dist (falling back to
+// fallbackMean/fallbackStdDev when dist is unconfigured), scaling each by
+// multiplier (e.g. a faultinjector elevated-latency multiplier), and returns
+// the last one sampled for a LatencyLastSeconds gauge.
+func (s *Simulator) sampleLatencies(dist LatencyWorkload, fallbackMean, fallbackStdDev, multiplier float64, observe func(float64)) float64 {
+	var last float64
+	for i := 0; i < dist.samples(); i++ {
+		last = dist.Dist.sample(s.rnd, fallbackMean, fallbackStdDev) * multiplier
+		observe(last)
+	}
+	return last
+}
+
+// NewSimulator returns a Simulator that drives the given disk and RAID IDs
+// on every tick, seeded from the current time.
+func NewSimulator(metrics *metricsPkg.AllMetrics, diskIDs, raidIDs []string) *Simulator {
+	return NewSimulatorWithSeed(time.Now().UnixNano(), metrics, diskIDs, raidIDs)
+}
+
+// NewSimulatorWithSeed is NewSimulator with an explicit PRNG seed: two
+// Simulators built with the same seed, disk/RAID IDs, and Workload (or
+// none) produce byte-identical AllMetrics state after the same number of
+// ticks, which NewSimulator's time-based seed can't guarantee.
+func NewSimulatorWithSeed(seed int64, metrics *metricsPkg.AllMetrics, diskIDs, raidIDs []string) *Simulator {
+	rnd := rand.New(rand.NewSource(seed))
+
+	s := &Simulator{
+		metrics:      metrics,
+		diskIDs:      diskIDs,
+		raidIDs:      raidIDs,
+		rnd:          rnd,
+		tickInterval: time.Second,
+		raidStates:   make(map[string]*raidState, len(raidIDs)),
+	}
+
+	s.cpuSeconds = 0
+	s.memoryBytes = 200*1024*1024 + float64(rnd.Intn(200*1024*1024))
+
+	return s
+}
+
+// SetWorkload switches the simulator from its built-in random ranges to
+// sampling from w's per-subsystem distributions, scaled over time by w's
+// phases. Call it before Start.
+func (s *Simulator) SetWorkload(w *Workload) {
+	s.workload = w
+}
+
+// SetFaultSchedule makes every subsequent tick consult fs for scripted
+// faults (disk failures, elevated latency, error bursts) in addition to
+// its normal workload/random-range behavior.
+func (s *Simulator) SetFaultSchedule(fs *faultinjector.FaultSchedule) {
+	s.faults = fs
+}
+
+// Start runs the simulator on interval until ctx is cancelled, registering
+// its goroutine on wg.
+func (s *Simulator) Start(ctx context.Context, wg *sync.WaitGroup, interval time.Duration) {
+	s.tickInterval = interval
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.tick()
+			}
+		}
+	}()
+}
+
+// advanceClock advances simClock by tickInterval, the same step tick() takes
+// at the top of every call. HybridCollector uses this directly instead of
+// tick(), since it drives each subsystem's simulate* method individually
+// depending on whether that subsystem's host reading is available.
+func (s *Simulator) advanceClock() {
+	s.simClock += s.tickInterval
+}
+
+func (s *Simulator) tick() {
+	s.advanceClock()
+
+	scale := 1.0
+	if s.workload != nil {
+		scale = s.workload.scaleAt(s.simClock)
+	}
+
+	s.simulateDisks(scale)
+	s.simulateRaid(scale)
+	s.simulateFuse(scale)
+	s.simulateProcess()
+}
+
+// simulateDisks samples op counts, sizes, and error probability from
+// s.workload.Disk (scaled by the current phase) when a Workload is set,
+// falling back to the original fixed rnd.Intn ranges otherwise.
+func (s *Simulator) simulateDisks(scale float64) {
+	for _, diskID := range s.diskIDs {
+		var reads, writes, readBytesPerOp, writeBytesPerOp, errorRate float64
+
+		if s.workload != nil {
+			w := s.workload.Disk
+			reads = w.Reads.sample(s.rnd) * scale
+			writes = w.Writes.sample(s.rnd) * scale
+			readBytesPerOp = w.ReadSize.sample(s.rnd)
+			writeBytesPerOp = w.WriteSize.sample(s.rnd)
+			errorRate = w.ErrorRate
+		} else {
+			reads = float64(s.rnd.Intn(100))
+			writes = float64(s.rnd.Intn(100))
+			readBytesPerOp = float64(4096 + s.rnd.Intn(64*1024-4096))
+			writeBytesPerOp = float64(4096 + s.rnd.Intn(64*1024-4096))
+			errorRate = 0.01
+		}
+
+		var extraErrors float64
+		var latency LatencyWorkload
+		if s.workload != nil {
+			latency = s.workload.Disk.Latency
+		}
+		for _, f := range s.faults.ActiveFor(diskID, s.simClock) {
+			switch f.Kind {
+			case faultinjector.KindFail:
+				reads, writes = 0, 0
+				errorRate = 1
+			case faultinjector.KindErrorBurst:
+				extraErrors += f.Rate * s.tickInterval.Seconds()
+			}
+		}
+
+		s.metrics.Disks.ReadOps.WithLabelValues(diskID).Add(reads)
+		s.metrics.Disks.WriteOps.WithLabelValues(diskID).Add(writes)
+		s.metrics.Disks.ReadBytes.WithLabelValues(diskID).Add(reads * readBytesPerOp)
+		s.metrics.Disks.WriteBytes.WithLabelValues(diskID).Add(writes * writeBytesPerOp)
+
+		readObserve := s.metrics.Disks.ReadLatency.WithLabelValues(diskID)
+		writeObserve := s.metrics.Disks.WriteLatency.WithLabelValues(diskID)
+		lastRead := s.sampleLatencies(latency, diskLatencyMeanDefault, diskLatencyStdDevDefault, 1.0, readObserve.Observe)
+		lastWrite := s.sampleLatencies(latency, diskLatencyMeanDefault, diskLatencyStdDevDefault, 1.0, writeObserve.Observe)
+		s.metrics.Disks.ReadLatencyLastSeconds.WithLabelValues(diskID).Set(lastRead)
+		s.metrics.Disks.WriteLatencyLastSeconds.WithLabelValues(diskID).Set(lastWrite)
+
+		queueDepth := float64(s.rnd.Intn(32))
+		s.metrics.Disks.QueueDepth.WithLabelValues(diskID).Set(queueDepth)
+
+		if s.rnd.Float64() < errorRate {
+			s.metrics.Disks.Errors.WithLabelValues(diskID).Inc()
+		}
+		if extraErrors > 0 {
+			s.metrics.Disks.Errors.WithLabelValues(diskID).Add(extraErrors)
+		}
+	}
+}
+
+func (s *Simulator) simulateRaid(scale float64) {
+	m := s.metrics.Raid
+	for _, raid := range s.raidIDs {
+		s.simulateRaidVolume(m, raid, scale)
+	}
+}
+
+func (s *Simulator) simulateRaidVolume(m *metricsPkg.RaidMetrics, raid string, scale float64) {
+	var reads, writes, readBytesPerOp, writeBytesPerOp, degradeProbability, rebuildStartProbability float64
+	var rebuildDuration time.Duration
+
+	if s.workload != nil {
+		w := s.workload.Raid
+		reads = w.Reads.sample(s.rnd) * scale
+		writes = w.Writes.sample(s.rnd) * scale
+		readBytesPerOp = w.ReadSize.sample(s.rnd)
+		writeBytesPerOp = w.WriteSize.sample(s.rnd)
+		degradeProbability = w.DegradeProbability
+		rebuildStartProbability = w.RebuildStartProbability
+		rebuildDuration = time.Duration(w.RebuildDuration.sample(s.rnd)) * time.Second
+	} else {
+		reads = float64(s.rnd.Intn(400))
+		writes = float64(s.rnd.Intn(400))
+		readBytesPerOp = float64(16*1024 + s.rnd.Intn(128*1024))
+		writeBytesPerOp = float64(16*1024 + s.rnd.Intn(128*1024))
+		degradeProbability = 0.05
+		rebuildStartProbability = 0.3
+		rebuildDuration = time.Duration(30+s.rnd.Intn(270)) * time.Second
+	}
+
+	for _, f := range s.faults.ActiveFor(raid, s.simClock) {
+		if f.Kind == faultinjector.KindParityDiskLoss {
+			degradeProbability = 1
+		}
+	}
+	forceFailed := s.faults.CascadingDiskFailures(s.diskIDs, s.simClock, 60*time.Second) >= 2
+
+	s.updateRaidIO(m, raid, reads, writes, readBytesPerOp, writeBytesPerOp)
+	s.updateRaidLatency(m, raid)
+	s.tickRaidHealth(m, raid, degradeProbability, rebuildStartProbability, rebuildDuration, forceFailed)
+	s.updateRaidSpecificMetrics(m, raid)
+}
+
+func (s *Simulator) updateRaidIO(m *metricsPkg.RaidMetrics, raid string, reads, writes, readBytesPerOp, writeBytesPerOp float64) {
+	m.ReadOps.WithLabelValues(raid).Add(reads)
+	m.WriteOps.WithLabelValues(raid).Add(writes)
+	m.ReadBytes.WithLabelValues(raid).Add(reads * readBytesPerOp)
+	m.WriteBytes.WithLabelValues(raid).Add(writes * writeBytesPerOp)
+}
+
+func (s *Simulator) updateRaidLatency(m *metricsPkg.RaidMetrics, raid string) {
+	var latency LatencyWorkload
+	if s.workload != nil {
+		latency = s.workload.Raid.Latency
+	}
+
+	readObserve := m.ReadLatency.WithLabelValues(raid)
+	writeObserve := m.WriteLatency.WithLabelValues(raid)
+	lastRead := s.sampleLatencies(latency, raidLatencyMeanDefault, raidLatencyStdDevDefault, 1.0, readObserve.Observe)
+	lastWrite := s.sampleLatencies(latency, raidLatencyMeanDefault, raidLatencyStdDevDefault, 1.0, writeObserve.Observe)
+	m.ReadLatencyLastSeconds.WithLabelValues(raid).Set(lastRead)
+	m.WriteLatencyLastSeconds.WithLabelValues(raid).Set(lastWrite)
+}
+
+func (s *Simulator) updateRaidSpecificMetrics(m *metricsPkg.RaidMetrics, raid string) {
+	switch raid {
+	case "raid1":
+		for _, diskID := range s.diskIDs {
+			m.Raid1ReadsFromDisk.WithLabelValues(raid, diskID).
+				Add(float64(s.rnd.Intn(200)))
+		}
+	case "raid3":
+		parityReads := float64(s.rnd.Intn(200))
+		if s.rebuilding(raid) {
+			// A RAID3 rebuild reconstructs the failed disk from parity,
+			// reading across every surviving disk for each stripe it
+			// repairs — on top of ordinary parity traffic, not instead of it.
+			parityReads += float64(len(s.diskIDs)) * float64(100+s.rnd.Intn(300))
+		}
+
+		m.Raid3ParityReads.WithLabelValues(raid).Add(parityReads)
+		m.Raid3ParityWrites.WithLabelValues(raid).Add(float64(s.rnd.Intn(200)))
+		m.Raid3PartialStripe.WithLabelValues(raid).Add(float64(s.rnd.Intn(50)))
+	}
+}
+
+func (s *Simulator) simulateFuse(scale float64) {
+	m := s.metrics.Fuse
+
+	var reads, writes, opens, fsyncs, readBytes, writeBytes, errorRate float64
+	var latency LatencyWorkload
+
+	if s.workload != nil {
+		w := s.workload.Fuse
+		reads = w.Reads.sample(s.rnd) * scale
+		writes = w.Writes.sample(s.rnd) * scale
+		opens = w.Opens.sample(s.rnd) * scale
+		fsyncs = w.Fsyncs.sample(s.rnd) * scale
+		readBytes = reads * w.ReadSize.sample(s.rnd)
+		writeBytes = writes * w.WriteSize.sample(s.rnd)
+		errorRate = w.ErrorRate
+		latency = w.Latency
+	} else {
+		reads = float64(s.rnd.Intn(500))
+		writes = float64(s.rnd.Intn(500))
+		opens = float64(s.rnd.Intn(200))
+		fsyncs = float64(s.rnd.Intn(100))
+		readBytes = reads * float64(4096+s.rnd.Intn(64*1024))
+		writeBytes = writes * float64(4096+s.rnd.Intn(64*1024))
+		errorRate = 0.02
+	}
+
+	latencyMultiplier := 1.0
+	var extraErrors float64
+	for _, f := range s.faults.ActiveFor("fuse", s.simClock) {
+		switch f.Kind {
+		case faultinjector.KindElevatedLatency:
+			latencyMultiplier *= f.Multiplier
+		case faultinjector.KindErrorBurst:
+			extraErrors += f.Rate * s.tickInterval.Seconds()
+		}
+	}
+
+	m.ReadOps.Add(reads)
+	m.WriteOps.Add(writes)
+	m.OpenOps.Add(opens)
+	m.FsyncOps.Add(fsyncs)
+	m.ReadBytes.Add(readBytes)
+	m.WriteBytes.Add(writeBytes)
+
+	lastRead := s.sampleLatencies(latency, fuseLatencyMeanDefault, fuseLatencyStdDevDefault, latencyMultiplier, m.ReadLatency.Observe)
+	lastWrite := s.sampleLatencies(latency, fuseLatencyMeanDefault, fuseLatencyStdDevDefault, latencyMultiplier, m.WriteLatency.Observe)
+	m.ReadLatencyLastSeconds.Set(lastRead)
+	m.WriteLatencyLastSeconds.Set(lastWrite)
+
+	if s.rnd.Float64() < errorRate {
+		m.Errors.Inc()
+	}
+	if extraErrors > 0 {
+		m.Errors.Add(extraErrors)
+	}
+}
+
+func (s *Simulator) simulateProcess() {
+	cpuDelta := 0.01 + s.rnd.Float64()*0.2
+	s.cpuSeconds += cpuDelta
+	s.metrics.Process.CPUSeconds.Set(s.cpuSeconds)
+
+	drift := float64(s.rnd.Intn(5 * 1024 * 1024))
+	if s.rnd.Intn(2) == 0 {
+		s.memoryBytes += drift
+	} else {
+		s.memoryBytes -= drift
+	}
+
+	if s.memoryBytes < 50*1024*1024 {
+		s.memoryBytes = 50 * 1024 * 1024
+	}
+
+	s.metrics.Process.ResidentMemory.Set(s.memoryBytes)
+}
@@ -0,0 +1,57 @@
+package simulator
+
+import (
+	"testing"
+	"time"
+
+	"metrics-gateway/internal/faultinjector"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestSimulateDisksAppliesKindFail(t *testing.T) {
+	sim, all := newTestSimulator(t)
+	sim.SetFaultSchedule(&faultinjector.FaultSchedule{Faults: []faultinjector.Fault{
+		{At: 0, Target: "disk0", Kind: faultinjector.KindFail},
+	}})
+
+	sim.simulateDisks(1.0)
+
+	if v := testutil.ToFloat64(all.Disks.ReadOps.WithLabelValues("disk0")); v != 0 {
+		t.Fatalf("expected failed disk0 to report zero read ops, got %f", v)
+	}
+	if v := testutil.ToFloat64(all.Disks.ReadOps.WithLabelValues("disk1")); v == 0 {
+		t.Fatalf("expected unaffected disk1 to keep reporting read ops, got %f", v)
+	}
+}
+
+func TestSimulateFuseAppliesErrorBurst(t *testing.T) {
+	sim, all := newTestSimulator(t)
+	sim.SetFaultSchedule(&faultinjector.FaultSchedule{Faults: []faultinjector.Fault{
+		{At: 0, Target: "fuse", Kind: faultinjector.KindErrorBurst, Rate: 50, Duration: time.Minute},
+	}})
+	sim.tickInterval = time.Second
+
+	sim.simulateFuse(1.0)
+
+	if v := testutil.ToFloat64(all.Fuse.Errors); v < 50 {
+		t.Fatalf("expected the error burst to add ~50 errors, got %f", v)
+	}
+}
+
+func TestSimulateRaidVolumeCascadingFailureForcesRaidFailed(t *testing.T) {
+	sim, all := newTestSimulator(t)
+	sim.SetFaultSchedule(&faultinjector.FaultSchedule{Faults: []faultinjector.Fault{
+		{At: 0, Target: "disk0", Kind: faultinjector.KindFail},
+		{At: 0, Target: "disk1", Kind: faultinjector.KindFail},
+	}})
+
+	sim.simulateRaidVolume(all.Raid, "raid0", 1.0)
+
+	if v := testutil.ToFloat64(all.Raid.FailedDisks.WithLabelValues("raid0")); v != 2 {
+		t.Fatalf("expected cascading disk failures to force raid0 to report 2 failed disks, got %f", v)
+	}
+	if v := testutil.ToFloat64(all.Raid.RebuildInProgress.WithLabelValues("raid0")); v != 0 {
+		t.Fatalf("expected a force-failed raid not to report a rebuild in progress, got %f", v)
+	}
+}
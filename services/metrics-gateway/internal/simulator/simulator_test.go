@@ -9,6 +9,7 @@ import (
 	metricsPkg "metrics-gateway/internal/metrics"
 
 	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
 )
 
 func newTestSimulator(t *testing.T) (*Simulator, *metricsPkg.AllMetrics) {
@@ -65,6 +66,39 @@ func TestSimulatorTickUpdatesRaidFuseAndProcessMetrics(t *testing.T) {
 	}
 }
 
+func TestSimulatorTickPopulatesLatencyLastSecondsGauges(t *testing.T) {
+	sim, all := newTestSimulator(t)
+
+	sim.tick()
+
+	if v := testutil.ToFloat64(all.Disks.ReadLatencyLastSeconds.WithLabelValues("disk0")); v <= 0 {
+		t.Fatalf("expected disk0 ReadLatencyLastSeconds > 0, got %f", v)
+	}
+	if v := testutil.ToFloat64(all.Raid.WriteLatencyLastSeconds.WithLabelValues("raid0")); v <= 0 {
+		t.Fatalf("expected raid0 WriteLatencyLastSeconds > 0, got %f", v)
+	}
+	if v := testutil.ToFloat64(all.Fuse.ReadLatencyLastSeconds); v <= 0 {
+		t.Fatalf("expected fuse ReadLatencyLastSeconds > 0, got %f", v)
+	}
+}
+
+func TestSimulateDisksObservesLatencySamplesPerWorkloadConfig(t *testing.T) {
+	sim, all := newTestSimulator(t)
+	sim.SetWorkload(&Workload{
+		Disk: DiskWorkload{Latency: LatencyWorkload{Samples: 4}},
+	})
+
+	sim.simulateDisks(1.0)
+
+	metric := &dto.Metric{}
+	if err := all.Disks.ReadLatency.WithLabelValues("disk0").Write(metric); err != nil {
+		t.Fatalf("write metric: %v", err)
+	}
+	if got := metric.GetHistogram().GetSampleCount(); got != 4 {
+		t.Fatalf("expected 4 latency samples observed, got %d", got)
+	}
+}
+
 func TestSimulatorStartStopsOnContextCancel(t *testing.T) {
 	sim, _ := newTestSimulator(t)
 
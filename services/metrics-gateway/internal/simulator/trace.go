@@ -0,0 +1,158 @@
+package simulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// metricSample is one named+labeled series' change on a single tick: the
+// delta since the previous tick for a counter, the current value for a
+// gauge, or the sum/count delta for a histogram (individual observations
+// aren't recoverable from Gather() output, so Replayer reconstructs each
+// tick's histogram delta as CountDelta observations of their average).
+type metricSample struct {
+	Name       string            `json:"name"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	Kind       string            `json:"kind"`
+	Value      float64           `json:"value,omitempty"`
+	SumDelta   float64           `json:"sum_delta,omitempty"`
+	CountDelta uint64            `json:"count_delta,omitempty"`
+}
+
+// TickTrace is every series that changed on one tick, in the form --record
+// writes to trace.jsonl (one TickTrace per line) and --replay reads back.
+type TickTrace struct {
+	Samples []metricSample `json:"samples"`
+}
+
+type cumulative struct {
+	value float64
+	count uint64
+}
+
+// Recorder captures the per-tick delta of every series a prometheus
+// registry exports under a metric name that has a replayTargets entry
+// (series under any other name are skipped) and appends it to a trace
+// file, so a later Replayer can
+// reproduce the same series without re-running the original Simulator (or
+// needing its PRNG seed).
+type Recorder struct {
+	reg  prometheus.Gatherer
+	f    *os.File
+	enc  *json.Encoder
+	prev map[string]cumulative
+}
+
+// NewRecorder creates (truncating) the trace file at path and returns a
+// Recorder gathering reg on each RecordTick call.
+func NewRecorder(reg prometheus.Gatherer, path string) (*Recorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("create trace file: %w", err)
+	}
+
+	return &Recorder{
+		reg:  reg,
+		f:    f,
+		enc:  json.NewEncoder(f),
+		prev: map[string]cumulative{},
+	}, nil
+}
+
+// Close closes the underlying trace file.
+func (r *Recorder) Close() error {
+	return r.f.Close()
+}
+
+// RecordTick gathers the registry's current state and appends the delta
+// since the previous call (or, on the first call, the absolute values) to
+// the trace file as one JSON line.
+func (r *Recorder) RecordTick() error {
+	families, err := r.reg.Gather()
+	if err != nil {
+		return fmt.Errorf("gather: %w", err)
+	}
+
+	var trace TickTrace
+
+	for _, mf := range families {
+		if _, ok := replayTargets[mf.GetName()]; !ok {
+			continue
+		}
+
+		for _, m := range mf.GetMetric() {
+			key := seriesKey(mf.GetName(), m.GetLabel())
+			labels := labelMap(m.GetLabel())
+
+			switch mf.GetType() {
+			case dto.MetricType_COUNTER:
+				cur := m.GetCounter().GetValue()
+				delta := cur - r.prev[key].value
+				r.prev[key] = cumulative{value: cur}
+				if delta != 0 {
+					trace.Samples = append(trace.Samples, metricSample{
+						Name: mf.GetName(), Labels: labels, Kind: "counter", Value: delta,
+					})
+				}
+			case dto.MetricType_GAUGE:
+				trace.Samples = append(trace.Samples, metricSample{
+					Name: mf.GetName(), Labels: labels, Kind: "gauge", Value: m.GetGauge().GetValue(),
+				})
+			case dto.MetricType_HISTOGRAM:
+				h := m.GetHistogram()
+				prev := r.prev[key]
+				sumDelta := h.GetSampleSum() - prev.value
+				countDelta := h.GetSampleCount() - prev.count
+				r.prev[key] = cumulative{value: h.GetSampleSum(), count: h.GetSampleCount()}
+				if countDelta != 0 {
+					trace.Samples = append(trace.Samples, metricSample{
+						Name: mf.GetName(), Labels: labels, Kind: "histogram",
+						SumDelta: sumDelta, CountDelta: countDelta,
+					})
+				}
+			}
+		}
+	}
+
+	return r.enc.Encode(trace)
+}
+
+func seriesKey(name string, labels []*dto.LabelPair) string {
+	pairs := make([]string, len(labels))
+	for i, lp := range labels {
+		pairs[i] = lp.GetName() + "=" + lp.GetValue()
+	}
+	sort.Strings(pairs)
+	return name + "\x00" + strings.Join(pairs, ",")
+}
+
+func labelMap(labels []*dto.LabelPair) map[string]string {
+	if len(labels) == 0 {
+		return nil
+	}
+	m := make(map[string]string, len(labels))
+	for _, lp := range labels {
+		m[lp.GetName()] = lp.GetValue()
+	}
+	return m
+}
+
+// decodeTrace reads one TickTrace from dec, returning io.EOF once the file
+// is exhausted.
+func decodeTrace(dec *json.Decoder) (TickTrace, error) {
+	var trace TickTrace
+	if err := dec.Decode(&trace); err != nil {
+		if err == io.EOF {
+			return TickTrace{}, io.EOF
+		}
+		return TickTrace{}, fmt.Errorf("decode trace: %w", err)
+	}
+	return trace, nil
+}
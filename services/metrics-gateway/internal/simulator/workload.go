@@ -0,0 +1,222 @@
+package simulator
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"time"
+
+	metricsPkg "metrics-gateway/internal/metrics"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Distribution samples a random magnitude from one of a few common shapes.
+// Kind selects the shape; the remaining fields are interpreted per kind:
+// "poisson" uses Lambda, "uniform" uses Min/Max, "normal" uses Mean/StdDev
+// (clamped at 0), and anything else (including the zero value) is treated
+// as a constant equal to Value.
+type Distribution struct {
+	Kind   string  `yaml:"dist"`
+	Lambda float64 `yaml:"lambda,omitempty"`
+	Min    float64 `yaml:"min,omitempty"`
+	Max    float64 `yaml:"max,omitempty"`
+	Mean   float64 `yaml:"mean,omitempty"`
+	StdDev float64 `yaml:"stddev,omitempty"`
+	Value  float64 `yaml:"value,omitempty"`
+}
+
+func (d Distribution) sample(rnd *rand.Rand) float64 {
+	switch d.Kind {
+	case "poisson":
+		return samplePoisson(rnd, d.Lambda)
+	case "uniform":
+		return d.Min + rnd.Float64()*(d.Max-d.Min)
+	case "normal":
+		if v := d.Mean + rnd.NormFloat64()*d.StdDev; v > 0 {
+			return v
+		}
+		return 0
+	default:
+		return d.Value
+	}
+}
+
+// samplePoisson uses Knuth's product-of-uniforms algorithm. It's only
+// called with the small-to-moderate lambdas (tens to low thousands) a
+// per-tick op count needs, where its O(lambda) cost is negligible.
+func samplePoisson(rnd *rand.Rand, lambda float64) float64 {
+	if lambda <= 0 {
+		return 0
+	}
+
+	l := math.Exp(-lambda)
+	k := 0
+	p := 1.0
+	for {
+		k++
+		p *= rnd.Float64()
+		if p <= l {
+			break
+		}
+	}
+	return float64(k - 1)
+}
+
+// LatencyDistribution models the per-sample shape of a subsystem's latency.
+// "lognormal" (the zero value/default) draws each sample as
+// exp(Mean + StdDev*Z) for a standard normal Z, in log-seconds; "bimodal"
+// mixes that same fast path with an occasional SlowMean/SlowStdDev "slow
+// path" sample (e.g. a GC pause or RAID rebuild stall), chosen with
+// probability SlowProbability.
+type LatencyDistribution struct {
+	Kind            string  `yaml:"kind,omitempty"`
+	Mean            float64 `yaml:"mean,omitempty"`
+	StdDev          float64 `yaml:"stddev,omitempty"`
+	SlowProbability float64 `yaml:"slowProbability,omitempty"`
+	SlowMean        float64 `yaml:"slowMean,omitempty"`
+	SlowStdDev      float64 `yaml:"slowStdDev,omitempty"`
+}
+
+// sample draws one latency value in seconds. A zero-valued Mean/StdDev
+// (meaning the scenario never configured this LatencyDistribution) falls
+// back to fallbackMean/fallbackStdDev, the subsystem's built-in defaults.
+func (d LatencyDistribution) sample(rnd *rand.Rand, fallbackMean, fallbackStdDev float64) float64 {
+	mean, stddev := d.Mean, d.StdDev
+	if mean == 0 && stddev == 0 {
+		mean, stddev = fallbackMean, fallbackStdDev
+	}
+	if d.Kind == "bimodal" && rnd.Float64() < d.SlowProbability {
+		mean, stddev = d.SlowMean, d.SlowStdDev
+	}
+	return math.Exp(mean + rnd.NormFloat64()*stddev)
+}
+
+// LatencyWorkload configures how a subsystem's per-tick latency samples are
+// generated: Samples observations are drawn per tick (1 when unset) from
+// Dist, and Buckets overrides that subsystem's default histogram bucket
+// boundaries.
+type LatencyWorkload struct {
+	Samples int                 `yaml:"samples,omitempty"`
+	Dist    LatencyDistribution `yaml:"dist,omitempty"`
+	Buckets []float64           `yaml:"buckets,omitempty"`
+}
+
+// samples returns how many latency observations a tick should draw: Samples
+// if set, else 1.
+func (l LatencyWorkload) samples() int {
+	if l.Samples > 0 {
+		return l.Samples
+	}
+	return 1
+}
+
+// DiskWorkload, RaidWorkload, and FuseWorkload configure the per-subsystem
+// distributions a Workload-driven tick samples from, replacing Simulator's
+// hard-coded rnd.Intn ranges.
+type DiskWorkload struct {
+	Reads     Distribution    `yaml:"reads"`
+	Writes    Distribution    `yaml:"writes"`
+	ReadSize  Distribution    `yaml:"readSize"`
+	WriteSize Distribution    `yaml:"writeSize"`
+	ErrorRate float64         `yaml:"errorRate"`
+	Latency   LatencyWorkload `yaml:"latency,omitempty"`
+}
+
+type RaidWorkload struct {
+	Reads              Distribution    `yaml:"reads"`
+	Writes             Distribution    `yaml:"writes"`
+	ReadSize           Distribution    `yaml:"readSize"`
+	WriteSize          Distribution    `yaml:"writeSize"`
+	DegradeProbability float64         `yaml:"degradeProbability"`
+	Latency            LatencyWorkload `yaml:"latency,omitempty"`
+
+	// RebuildStartProbability is the per-tick chance a degraded array
+	// begins rebuilding; RebuildDuration estimates how long that rebuild
+	// will take, in seconds, once it starts.
+	RebuildStartProbability float64      `yaml:"rebuildStartProbability"`
+	RebuildDuration         Distribution `yaml:"rebuildDuration"`
+}
+
+type FuseWorkload struct {
+	Reads     Distribution    `yaml:"reads"`
+	Writes    Distribution    `yaml:"writes"`
+	Opens     Distribution    `yaml:"opens"`
+	Fsyncs    Distribution    `yaml:"fsyncs"`
+	ReadSize  Distribution    `yaml:"readSize"`
+	WriteSize Distribution    `yaml:"writeSize"`
+	ErrorRate float64         `yaml:"errorRate"`
+	Latency   LatencyWorkload `yaml:"latency,omitempty"`
+}
+
+// Phase scales a Workload's rates by Scale for Duration before the
+// simulator advances to the next phase, looping back to the first once the
+// last one ends, e.g. "ramp-up 30s -> steady 5m -> burst 30s -> idle 1m".
+type Phase struct {
+	Name     string        `yaml:"name"`
+	Duration time.Duration `yaml:"duration"`
+	Scale    float64       `yaml:"scale"`
+}
+
+// Workload is a complete scenario: per-subsystem distributions plus a
+// sequence of phases that scale their rates over time.
+type Workload struct {
+	Name   string       `yaml:"name"`
+	Disk   DiskWorkload `yaml:"disk"`
+	Raid   RaidWorkload `yaml:"raid"`
+	Fuse   FuseWorkload `yaml:"fuse"`
+	Phases []Phase      `yaml:"phases"`
+}
+
+// LoadWorkload reads a scenario from a YAML (or JSON, which parses fine as
+// YAML) file.
+func LoadWorkload(path string) (*Workload, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read workload file: %w", err)
+	}
+
+	var w Workload
+	if err := yaml.Unmarshal(data, &w); err != nil {
+		return nil, fmt.Errorf("parse workload file: %w", err)
+	}
+	return &w, nil
+}
+
+// LatencyBuckets translates this Workload's per-tier bucket overrides into
+// metrics.LatencyBuckets, ready to pass to metrics.NewMetricsRegistryWithBuckets.
+func (w *Workload) LatencyBuckets() metricsPkg.LatencyBuckets {
+	return metricsPkg.LatencyBuckets{
+		Disk: w.Disk.Latency.Buckets,
+		Raid: w.Raid.Latency.Buckets,
+		Fuse: w.Fuse.Latency.Buckets,
+	}
+}
+
+// scaleAt returns the phase scale in effect `elapsed` after the workload
+// started, looping back to the first phase once the last one ends. A
+// workload with no phases (or with phases summing to zero duration) always
+// scales at 1.0.
+func (w *Workload) scaleAt(elapsed time.Duration) float64 {
+	if len(w.Phases) == 0 {
+		return 1.0
+	}
+
+	var total time.Duration
+	for _, p := range w.Phases {
+		total += p.Duration
+	}
+	if total <= 0 {
+		return 1.0
+	}
+
+	pos := elapsed % total
+	for _, p := range w.Phases {
+		if pos < p.Duration {
+			return p.Scale
+		}
+		pos -= p.Duration
+	}
+	return w.Phases[len(w.Phases)-1].Scale
+}
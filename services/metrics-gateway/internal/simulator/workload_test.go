@@ -0,0 +1,147 @@
+package simulator
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestDistributionUniformStaysInRange(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	d := Distribution{Kind: "uniform", Min: 10, Max: 20}
+
+	for i := 0; i < 100; i++ {
+		v := d.sample(rnd)
+		if v < 10 || v > 20 {
+			t.Fatalf("uniform sample %f out of [10, 20]", v)
+		}
+	}
+}
+
+func TestDistributionConstantReturnsValue(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	d := Distribution{Value: 42}
+
+	if got := d.sample(rnd); got != 42 {
+		t.Fatalf("constant sample = %f, want 42", got)
+	}
+}
+
+func TestDistributionPoissonNonNegative(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	d := Distribution{Kind: "poisson", Lambda: 50}
+
+	for i := 0; i < 100; i++ {
+		if v := d.sample(rnd); v < 0 {
+			t.Fatalf("poisson sample %f < 0", v)
+		}
+	}
+}
+
+func TestWorkloadScaleAtLoopsThroughPhases(t *testing.T) {
+	w := &Workload{
+		Phases: []Phase{
+			{Name: "ramp-up", Duration: 10 * time.Second, Scale: 0.5},
+			{Name: "steady", Duration: 20 * time.Second, Scale: 1.0},
+		},
+	}
+
+	cases := []struct {
+		elapsed time.Duration
+		want    float64
+	}{
+		{0, 0.5},
+		{5 * time.Second, 0.5},
+		{15 * time.Second, 1.0},
+		{30 * time.Second, 0.5},  // wraps back to ramp-up
+		{35 * time.Second, 0.5},
+	}
+	for _, c := range cases {
+		if got := w.scaleAt(c.elapsed); got != c.want {
+			t.Errorf("scaleAt(%s) = %f, want %f", c.elapsed, got, c.want)
+		}
+	}
+}
+
+func TestWorkloadScaleAtWithNoPhasesIsOne(t *testing.T) {
+	w := &Workload{}
+	if got := w.scaleAt(time.Hour); got != 1.0 {
+		t.Fatalf("scaleAt with no phases = %f, want 1.0", got)
+	}
+}
+
+func TestSimulatorWithWorkloadDrivesMetrics(t *testing.T) {
+	sim, all := newTestSimulator(t)
+	sim.SetWorkload(BuiltinWorkloads["small-random-io"])
+
+	sim.tick()
+
+	if v := testutil.ToFloat64(all.Disks.ReadOps.WithLabelValues("disk0")); v == 0 {
+		t.Fatalf("expected disk read ops for disk0 > 0 under a workload, got %f", v)
+	}
+	if v := testutil.ToFloat64(all.Fuse.ReadOps); v == 0 {
+		t.Fatalf("expected fuse read ops > 0 under a workload, got %f", v)
+	}
+}
+
+func TestLatencyDistributionLognormalFallsBackToDefault(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	d := LatencyDistribution{}
+
+	for i := 0; i < 100; i++ {
+		if v := d.sample(rnd, -6, 0.1); v <= 0 {
+			t.Fatalf("lognormal sample %f should be strictly positive", v)
+		}
+	}
+}
+
+func TestLatencyDistributionBimodalUsesSlowPath(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	d := LatencyDistribution{
+		Kind:            "bimodal",
+		Mean:            -10,
+		StdDev:          0.01,
+		SlowProbability: 1,
+		SlowMean:        -2,
+		SlowStdDev:      0.01,
+	}
+
+	// SlowProbability 1 always takes the slow path, which samples around
+	// exp(-2), several orders of magnitude above the fast path's exp(-10).
+	if v := d.sample(rnd, 0, 0); v < 0.05 {
+		t.Fatalf("expected a slow-path sample near exp(-2), got %f", v)
+	}
+}
+
+func TestLatencyWorkloadSamplesDefaultsToOne(t *testing.T) {
+	if got := (LatencyWorkload{}).samples(); got != 1 {
+		t.Fatalf("samples() with no Samples set = %d, want 1", got)
+	}
+	if got := (LatencyWorkload{Samples: 5}).samples(); got != 5 {
+		t.Fatalf("samples() = %d, want 5", got)
+	}
+}
+
+func TestWorkloadLatencyBucketsPassesThroughOverrides(t *testing.T) {
+	w := &Workload{
+		Disk: DiskWorkload{Latency: LatencyWorkload{Buckets: []float64{0.001, 0.01}}},
+	}
+
+	b := w.LatencyBuckets()
+	if len(b.Disk) != 2 || b.Disk[1] != 0.01 {
+		t.Fatalf("LatencyBuckets().Disk = %v, want [0.001 0.01]", b.Disk)
+	}
+	if b.Raid != nil || b.Fuse != nil {
+		t.Fatalf("expected unconfigured tiers to stay nil, got Raid=%v Fuse=%v", b.Raid, b.Fuse)
+	}
+}
+
+func TestBuiltinWorkloadsAreNamedConsistently(t *testing.T) {
+	for name, w := range BuiltinWorkloads {
+		if w.Name != name {
+			t.Errorf("BuiltinWorkloads[%q].Name = %q, want %q", name, w.Name, name)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+// Package tracing wires the ingest gRPC server into an OpenTelemetry
+// distributed-tracing pipeline. It is opt-in: when no OTLP endpoint is
+// configured, Config.Enabled is false and every exported interceptor
+// degrades to a no-op that defers to the global (no-op) TracerProvider.
+package tracing
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config controls the OTLP exporter and sampler used for server spans.
+type Config struct {
+	Enabled bool
+
+	ServiceName string
+
+	// OTLPProtocol is either "grpc" or "http".
+	OTLPProtocol string
+	OTLPEndpoint string
+
+	SamplingRatio float64
+	BatchTimeout  time.Duration
+}
+
+// LoadConfigFromEnv builds a Config from OTEL_* environment variables,
+// following the conventions of the OpenTelemetry SDK autoconfiguration spec.
+// Tracing is enabled only when OTEL_EXPORTER_OTLP_ENDPOINT is set.
+func LoadConfigFromEnv() Config {
+	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+
+	return Config{
+		Enabled:       endpoint != "",
+		ServiceName:   getenv("OTEL_SERVICE_NAME", "metrics-gateway"),
+		OTLPProtocol:  getenv("OTEL_EXPORTER_OTLP_PROTOCOL", "grpc"),
+		OTLPEndpoint:  endpoint,
+		SamplingRatio: parseFloat(getenv("OTEL_TRACES_SAMPLER_ARG", "1.0"), 1.0),
+		BatchTimeout:  parseDurationMS(getenv("OTEL_BSP_SCHEDULE_DELAY", "5000"), 5*time.Second),
+	}
+}
+
+func getenv(k, def string) string {
+	v := os.Getenv(k)
+	if v == "" {
+		return def
+	}
+	return v
+}
+
+func parseFloat(s string, def float64) float64 {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return def
+	}
+	return f
+}
+
+func parseDurationMS(s string, def time.Duration) time.Duration {
+	ms, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || ms < 0 {
+		return def
+	}
+	return time.Duration(ms) * time.Millisecond
+}
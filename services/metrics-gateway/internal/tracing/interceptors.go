@@ -0,0 +1,121 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	grpcstatus "google.golang.org/grpc/status"
+)
+
+const tracerName = "metrics-gateway/internal/server"
+
+// propagator understands W3C traceparent/tracestate headers, falling back to
+// B3 (single or multi-header) for producers that haven't migrated yet.
+var propagator = propagation.NewCompositeTextMapPropagator(
+	b3.New(),
+	propagation.TraceContext{},
+	propagation.Baggage{},
+)
+
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	vals := metadata.MD(c).Get(key)
+	if len(vals) == 0 {
+		return ""
+	}
+	return vals[0]
+}
+
+func (c metadataCarrier) Set(key, value string) { metadata.MD(c).Set(key, value) }
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func extract(ctx context.Context) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		md = metadata.MD{}
+	}
+	return propagator.Extract(ctx, metadataCarrier(md))
+}
+
+func startServerSpan(ctx context.Context, method string) (context.Context, trace.Span) {
+	tracer := otel.Tracer(tracerName)
+	ctx = extract(ctx)
+	return tracer.Start(ctx, method, trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attribute.String("rpc.system", "grpc")))
+}
+
+func sourceIDFromContext(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if vals := md.Get("x-source-id"); len(vals) > 0 {
+		return vals[0]
+	}
+	return ""
+}
+
+func finishSpan(span trace.Span, err error) {
+	st := grpcstatus.Convert(err)
+	span.SetAttributes(attribute.Int64("rpc.grpc.status_code", int64(st.Code())))
+	if err != nil {
+		span.SetStatus(codes.Error, st.Message())
+	} else {
+		span.SetStatus(codes.Ok, "")
+	}
+	span.End()
+}
+
+// UnaryServerInterceptor starts a server span per unary RPC, named after the
+// full method, extracting a parent context from incoming W3C/B3 headers.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, span := startServerSpan(ctx, info.FullMethod)
+		if sid := sourceIDFromContext(ctx); sid != "" {
+			span.SetAttributes(attribute.String("peer.source_id", sid))
+		}
+
+		resp, err := handler(ctx, req)
+		finishSpan(span, err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor starts a server span per streaming RPC, named
+// after the full method, extracting a parent context from incoming
+// W3C/B3 headers and threading it into the wrapped stream's Context().
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, span := startServerSpan(ss.Context(), info.FullMethod)
+		span.SetAttributes(
+			attribute.Bool("rpc.grpc.client_stream", info.IsClientStream),
+			attribute.Bool("rpc.grpc.server_stream", info.IsServerStream),
+		)
+
+		err := handler(srv, &tracedServerStream{ServerStream: ss, ctx: ctx})
+		finishSpan(span, err)
+		return err
+	}
+}
+
+type tracedServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *tracedServerStream) Context() context.Context { return s.ctx }
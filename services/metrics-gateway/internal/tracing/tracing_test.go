@@ -0,0 +1,86 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestLoadConfigFromEnvDisabledWithoutEndpoint(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+
+	cfg := LoadConfigFromEnv()
+	if cfg.Enabled {
+		t.Fatal("expected tracing to be disabled without an OTLP endpoint")
+	}
+}
+
+func TestLoadConfigFromEnvParsesFields(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "localhost:4317")
+	t.Setenv("OTEL_SERVICE_NAME", "gateway-test")
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "http")
+	t.Setenv("OTEL_TRACES_SAMPLER_ARG", "0.25")
+	t.Setenv("OTEL_BSP_SCHEDULE_DELAY", "250")
+
+	cfg := LoadConfigFromEnv()
+	if !cfg.Enabled {
+		t.Fatal("expected tracing to be enabled with an OTLP endpoint set")
+	}
+	if cfg.ServiceName != "gateway-test" || cfg.OTLPProtocol != "http" || cfg.OTLPEndpoint != "localhost:4317" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	if cfg.SamplingRatio != 0.25 || cfg.BatchTimeout != 250*time.Millisecond {
+		t.Fatalf("unexpected sampling/batch config: %+v", cfg)
+	}
+}
+
+func TestNewProviderNoopWhenDisabled(t *testing.T) {
+	shutdown, err := NewProvider(context.Background(), Config{Enabled: false})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("expected no-op shutdown to succeed, got %v", err)
+	}
+}
+
+func TestUnaryServerInterceptorRunsHandler(t *testing.T) {
+	unary := UnaryServerInterceptor()
+
+	called := false
+	resp, err := unary(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"},
+		func(ctx context.Context, req any) (any, error) {
+			called = true
+			if span := trace.SpanFromContext(ctx); span == nil {
+				t.Fatal("expected a span to be present in the handler context")
+			}
+			return "ok", nil
+		})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !called {
+		t.Fatal("expected handler to be invoked")
+	}
+	if resp != "ok" {
+		t.Fatalf("unexpected response: %v", resp)
+	}
+}
+
+func TestExtractPrefersTraceparentHeader(t *testing.T) {
+	md := metadata.Pairs("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+
+	extracted := extract(ctx)
+	sc := trace.SpanContextFromContext(extracted)
+	if !sc.IsValid() {
+		t.Fatal("expected a valid span context extracted from traceparent header")
+	}
+	if sc.TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("unexpected trace id: %s", sc.TraceID())
+	}
+}